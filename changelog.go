@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// changelogFile is the name of the attached full changelog.
+const changelogFile = "CHANGELOG.md"
+
+var conventionalCommit = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+type (
+	// Changelog groups the commits of a release by conventional commit type.
+	Changelog struct {
+		Tag      string
+		Previous string
+		Breaking []ChangelogEntry
+		Features []ChangelogEntry
+		Fixes    []ChangelogEntry
+		Others   []ChangelogEntry
+	}
+
+	// ChangelogEntry is a commit listed in the changelog.
+	ChangelogEntry struct {
+		Type        string
+		Scope       string
+		Description string
+		ShortSha    string
+	}
+)
+
+// newChangelog groups the commits, parsing conventional commit prefixes.
+func newChangelog(tag, previous string, commits []CommitEntry) Changelog {
+	c := Changelog{Tag: tag, Previous: previous}
+	for _, commit := range commits {
+		entry := ChangelogEntry{Description: commit.Subject, ShortSha: commit.ShortSha}
+		breaking := strings.Contains(commit.Body, "BREAKING CHANGE")
+		if m := conventionalCommit.FindStringSubmatch(commit.Subject); m != nil {
+			entry.Type = strings.ToLower(m[1])
+			entry.Scope = m[2]
+			entry.Description = m[4]
+			breaking = breaking || m[3] == "!"
+		}
+
+		switch {
+		case breaking:
+			c.Breaking = append(c.Breaking, entry)
+		case entry.Type == "feat":
+			c.Features = append(c.Features, entry)
+		case entry.Type == "fix":
+			c.Fixes = append(c.Fixes, entry)
+		default:
+			c.Others = append(c.Others, entry)
+		}
+	}
+	return c
+}
+
+// String formats the entry as a markdown list item.
+func (e ChangelogEntry) String() string {
+	if e.Scope != "" {
+		return fmt.Sprintf("- **%s:** %s (%s)", e.Scope, e.Description, e.ShortSha)
+	}
+	return fmt.Sprintf("- %s (%s)", e.Description, e.ShortSha)
+}
+
+// groups returns the non-empty changelog sections with their catalog key.
+func (c Changelog) groups() []struct {
+	key     string
+	entries []ChangelogEntry
+} {
+	all := []struct {
+		key     string
+		entries []ChangelogEntry
+	}{
+		{"breaking_changes", c.Breaking},
+		{"features", c.Features},
+		{"fixes", c.Fixes},
+		{"other_changes", c.Others},
+	}
+
+	groups := all[:0]
+	for _, g := range all {
+		if len(g.entries) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// loadChangelog computes the changelog of a tag build from the previous
+// tag in the local git checkout.
+func (p *Plugin) loadChangelog(ctx context.Context) {
+	if !p.Config.Changelog || p.Build.Event != "tag" {
+		return
+	}
+
+	tag := p.Build.Tag
+	if tag == "" {
+		tag = "HEAD"
+	}
+
+	// Without a previous tag the changelog covers the whole history.
+	previous, _ := p.git(ctx, "describe", "--tags", "--abbrev=0", tag+"^")
+	commits, err := p.commitLog(ctx, previous, tag)
+	if err != nil {
+		if p.Config.Debug {
+			log.Printf("failed to generate changelog: %v", err)
+		}
+		return
+	}
+
+	p.Changelog = newChangelog(p.Build.Tag, previous, commits)
+	if p.Config.ChangelogAttach && p.changelogTruncated() {
+		p.attachments = append(p.attachments, attachment{
+			Name:    changelogFile,
+			Content: []byte(p.changelogMarkdown()),
+		})
+	}
+}
+
+// changelogFields renders the changelog sections as embed fields.
+func (p *Plugin) changelogFields() []EmbedFieldObject {
+	var fields []EmbedFieldObject
+	for _, g := range p.Changelog.groups() {
+		lines := make([]string, 0, len(g.entries))
+		for _, e := range g.entries {
+			lines = append(lines, e.String())
+		}
+		fields = append(fields, EmbedFieldObject{
+			Name:  p.tr(g.key),
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+	return fields
+}
+
+// changelogTruncated reports whether the changelog exceeds the embed limits.
+func (p *Plugin) changelogTruncated() bool {
+	total := 0
+	for _, f := range p.changelogFields() {
+		if utf8.RuneCountInString(f.Value) > maxFieldValueLength {
+			return true
+		}
+		total += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	return total > maxEmbedLength/2
+}
+
+// changelogMarkdown renders the full changelog as markdown.
+func (p *Plugin) changelogMarkdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", p.Changelog.Tag)
+	for _, g := range p.Changelog.groups() {
+		fmt.Fprintf(&b, "\n## %s\n\n", p.tr(g.key))
+		for _, e := range g.entries {
+			b.WriteString(e.String() + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewChangelog(t *testing.T) {
+	c := newChangelog("v1.1.0", "v1.0.0", []CommitEntry{
+		{ShortSha: "a1", Subject: "feat(api): add webhooks"},
+		{ShortSha: "a2", Subject: "fix: handle empty message"},
+		{ShortSha: "a3", Subject: "refactor!: drop legacy flags"},
+		{ShortSha: "a4", Subject: "feat: new config", Body: "BREAKING CHANGE: config renamed"},
+		{ShortSha: "a5", Subject: "update README"},
+	})
+
+	assert.Equal(t, []ChangelogEntry{{Type: "feat", Scope: "api", Description: "add webhooks", ShortSha: "a1"}}, c.Features)
+	assert.Equal(t, []ChangelogEntry{{Type: "fix", Description: "handle empty message", ShortSha: "a2"}}, c.Fixes)
+	assert.Len(t, c.Breaking, 2)
+	assert.Equal(t, []ChangelogEntry{{Description: "update README", ShortSha: "a5"}}, c.Others)
+	assert.Equal(t, "- **api:** add webhooks (a1)", c.Features[0].String())
+}
+
+func TestTagChangelog(t *testing.T) {
+	repo := newGitRepo(t)
+	repo.commit("initial", nil)
+	repo.run("tag", "v1.0.0")
+	repo.commit("feat: add changelog", nil)
+	repo.commit("fix: typo", nil)
+	repo.run("tag", "v1.1.0")
+
+	p := Plugin{
+		Build:  Build{Event: "tag", Tag: "v1.1.0"},
+		Config: Config{Workspace: repo.dir, Changelog: true, ChangelogAttach: true},
+	}
+	p.loadChangelog(context.Background())
+	assert.Equal(t, "v1.0.0", p.Changelog.Previous)
+	assert.Empty(t, p.attachments)
+
+	fields := p.Template().Fields
+	assert.Len(t, fields, 2)
+	assert.Equal(t, "Features", fields[0].Name)
+	assert.Contains(t, fields[0].Value, "- add changelog (")
+	assert.Equal(t, "Fixes", fields[1].Name)
+}
+
+func TestTagChangelogAttachment(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	repo := newGitRepo(t)
+	for i := 0; i < 60; i++ {
+		repo.commit(fmt.Sprintf("feat: a rather long feature description number %d", i), nil)
+	}
+	repo.run("tag", "v1.0.0")
+
+	plugin := Plugin{
+		Build: Build{Event: "tag", Tag: "v1.0.0"},
+		Config: Config{
+			webhookURL:      srv.URL + "/api/webhooks/1/token",
+			AllowedHosts:    testHosts,
+			Workspace:       repo.dir,
+			Changelog:       true,
+			ChangelogAttach: true,
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 2)
+	assert.Contains(t, bodies[0], `"name":"Features"`)
+	assert.Contains(t, bodies[1], `filename="CHANGELOG.md"`)
+	assert.Contains(t, bodies[1], "# v1.0.0\n\n## Features\n\n- a rather long feature description number 59")
+	assert.Equal(t, 60, strings.Count(bodies[1], "- a rather long"))
+}
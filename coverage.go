@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Coverage counts the covered lines, or statements for Go
+	// coverprofiles, of a coverage report.
+	Coverage struct {
+		Covered int
+		Total   int
+	}
+
+	coberturaReport struct {
+		LinesCovered int                `xml:"lines-covered,attr"`
+		LinesValid   int                `xml:"lines-valid,attr"`
+		Packages     []coberturaPackage `xml:"packages>package"`
+	}
+
+	coberturaPackage struct {
+		Classes []struct {
+			Filename string `xml:"filename,attr"`
+			Lines    []struct {
+				Number int `xml:"number,attr"`
+				Hits   int `xml:"hits,attr"`
+			} `xml:"lines>line"`
+		} `xml:"classes>class"`
+	}
+)
+
+// Percent returns the coverage in percent.
+func (c Coverage) Percent() float64 {
+	if c.Total == 0 {
+		return 0
+	}
+	return float64(c.Covered) * 100 / float64(c.Total)
+}
+
+// parseCoverage reads a Cobertura XML, LCOV or Go coverprofile report.
+func parseCoverage(path string) (Coverage, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return Coverage{}, err
+	}
+
+	trimmed := bytes.TrimSpace(b)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return parseCoverprofile(b)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseCobertura(b)
+	case bytes.Contains(b, []byte("SF:")):
+		return parseLCOV(b)
+	}
+	return Coverage{}, errors.New("unknown coverage format")
+}
+
+// parseCobertura reads a Cobertura XML report, counting the lines when the
+// totals are missing.
+func parseCobertura(b []byte) (Coverage, error) {
+	var r coberturaReport
+	if err := xml.Unmarshal(b, &r); err != nil {
+		return Coverage{}, fmt.Errorf("invalid cobertura report: %w", err)
+	}
+	if r.LinesValid > 0 {
+		return Coverage{Covered: r.LinesCovered, Total: r.LinesValid}, nil
+	}
+
+	hits := map[string]bool{}
+	for _, pkg := range r.Packages {
+		for _, class := range pkg.Classes {
+			for _, line := range class.Lines {
+				key := class.Filename + ":" + strconv.Itoa(line.Number)
+				hits[key] = hits[key] || line.Hits > 0
+			}
+		}
+	}
+	return countHits(hits), nil
+}
+
+// parseLCOV reads an LCOV tracefile.
+func parseLCOV(b []byte) (Coverage, error) {
+	var c Coverage
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || (key != "LF" && key != "LH") {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return Coverage{}, fmt.Errorf("invalid lcov line %s: %w", scanner.Text(), err)
+		}
+		if key == "LF" {
+			c.Total += n
+		} else {
+			c.Covered += n
+		}
+	}
+	return c, scanner.Err()
+}
+
+// parseCoverprofile reads a Go coverprofile. Blocks listed more than once,
+// as with -coverpkg, count as covered when any of them is.
+func parseCoverprofile(b []byte) (Coverage, error) {
+	stmts := map[string]int{}
+	hits := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return Coverage{}, fmt.Errorf("invalid coverprofile line: %s", line)
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return Coverage{}, fmt.Errorf("invalid coverprofile line: %s", line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return Coverage{}, fmt.Errorf("invalid coverprofile line: %s", line)
+		}
+		stmts[fields[0]] = n
+		hits[fields[0]] = hits[fields[0]] || count > 0
+	}
+
+	var c Coverage
+	for block, n := range stmts {
+		c.Total += n
+		if hits[block] {
+			c.Covered += n
+		}
+	}
+	return c, scanner.Err()
+}
+
+func countHits(hits map[string]bool) Coverage {
+	c := Coverage{Total: len(hits)}
+	for _, hit := range hits {
+		if hit {
+			c.Covered++
+		}
+	}
+	return c
+}
+
+// coverageBaseline returns the baseline coverage in percent, given as a
+// number, a file containing a number or a coverage report.
+func coverageBaseline(baseline string) (float64, error) {
+	if v, err := strconv.ParseFloat(strings.TrimSuffix(baseline, "%"), 64); err == nil {
+		return v, nil
+	}
+
+	b, err := os.ReadFile(filepath.Clean(baseline))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read coverage baseline: %w", err)
+	}
+	if v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(string(b)), "%"), 64); err == nil {
+		return v, nil
+	}
+
+	c, err := parseCoverage(baseline)
+	if err != nil {
+		return 0, err
+	}
+	return c.Percent(), nil
+}
+
+// coverageDelta formats the change of coverage with an up or down
+// indicator.
+func coverageDelta(delta float64) string {
+	switch {
+	case delta >= 0.05:
+		return fmt.Sprintf("▲ +%.1f%%", delta)
+	case delta <= -0.05:
+		return fmt.Sprintf("▼ %.1f%%", delta)
+	}
+	return "= 0.0%"
+}
+
+// coverageReport renders the coverage with its change from the baseline
+// and checks it against the threshold.
+func (p *Plugin) coverageReport(c Coverage, baseline *float64) report {
+	percent := c.Percent()
+	r := report{Status: ReportSuccess}
+
+	value := fmt.Sprintf("%.1f%%", percent)
+	if baseline != nil {
+		delta := percent - *baseline
+		value += fmt.Sprintf(" (%s)", coverageDelta(delta))
+		if delta <= -0.05 {
+			r.Status = ReportWarning
+		}
+	}
+
+	threshold := p.Config.CoverageThreshold
+	if threshold > 0 && percent < threshold {
+		r.Status = ReportFailure
+		value += "\n" + p.tr("coverage_below", threshold)
+		if p.Config.CoverageFail {
+			r.Err = fmt.Errorf("coverage %.1f%% is below the threshold of %.1f%%", percent, threshold)
+		}
+	}
+
+	r.Fields = []EmbedFieldObject{{Name: p.tr("coverage"), Value: value}}
+	return r
+}
+
+// loadCoverage summarizes the coverage reports matching the configured
+// globs.
+func (p *Plugin) loadCoverage() error {
+	if len(p.Config.Coverage) == 0 {
+		return nil
+	}
+
+	files, err := expandGlobs(p.Config.Coverage)
+	if err != nil {
+		return fmt.Errorf("invalid coverage pattern: %w", err)
+	}
+	if len(files) == 0 {
+		log.Printf("no coverage report found for %s", strings.Join(p.Config.Coverage, ", "))
+		return nil
+	}
+
+	// a broken report must not stop the notification
+	var (
+		total  Coverage
+		parsed int
+	)
+	for _, f := range files {
+		c, err := parseCoverage(f)
+		if err != nil {
+			log.Printf("skipping coverage report %s: %v", f, err)
+			continue
+		}
+		total.Covered += c.Covered
+		total.Total += c.Total
+		parsed++
+	}
+	if parsed == 0 {
+		return nil
+	}
+
+	var baseline *float64
+	if p.Config.CoverageBaseline != "" {
+		v, err := coverageBaseline(p.Config.CoverageBaseline)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			log.Printf("coverage baseline %s not found", p.Config.CoverageBaseline)
+		case err != nil:
+			return err
+		default:
+			baseline = &v
+		}
+	}
+
+	p.addReport(p.coverageReport(total, baseline), false)
+	return nil
+}
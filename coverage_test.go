@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	coberturaReportXML = `<?xml version="1.0" ?>
+<coverage line-rate="0.75" lines-covered="3" lines-valid="4">
+</coverage>`
+
+	coberturaLinesXML = `<coverage>
+  <packages><package><classes>
+    <class filename="a.py"><lines>
+      <line number="1" hits="1"/><line number="2" hits="0"/>
+    </lines></class>
+    <class filename="a.py"><lines>
+      <line number="2" hits="3"/><line number="3" hits="0"/>
+    </lines></class>
+  </classes></package></packages>
+</coverage>`
+
+	lcovReport = `TN:
+SF:src/a.js
+DA:1,1
+LF:10
+LH:8
+end_of_record
+SF:src/b.js
+LF:10
+LH:2
+end_of_record
+`
+
+	coverProfile = `mode: set
+example.com/a/a.go:3.10,5.2 2 1
+example.com/a/a.go:7.10,9.2 3 0
+example.com/a/a.go:3.10,5.2 2 0
+example.com/a/b.go:1.1,2.2 5 1
+`
+)
+
+func writeCoverage(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	return file
+}
+
+func TestParseCoverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Coverage
+	}{
+		{"coverage.xml", coberturaReportXML, Coverage{Covered: 3, Total: 4}},
+		{"coverage.xml", coberturaLinesXML, Coverage{Covered: 2, Total: 3}},
+		{"lcov.info", lcovReport, Coverage{Covered: 10, Total: 20}},
+		{"cover.out", coverProfile, Coverage{Covered: 7, Total: 10}},
+	}
+	for _, tt := range tests {
+		c, err := parseCoverage(writeCoverage(t, tt.name, tt.content))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, c)
+	}
+
+	_, err := parseCoverage(writeCoverage(t, "cover.txt", "hello"))
+	assert.ErrorContains(t, err, "unknown coverage format")
+}
+
+func TestCoverageBaseline(t *testing.T) {
+	v, err := coverageBaseline("82.5%")
+	assert.NoError(t, err)
+	assert.Equal(t, 82.5, v)
+
+	v, err = coverageBaseline(writeCoverage(t, "baseline.txt", "60\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 60.0, v)
+
+	v, err = coverageBaseline(writeCoverage(t, "cover.out", coverProfile))
+	assert.NoError(t, err)
+	assert.Equal(t, 70.0, v)
+}
+
+func TestCoverageReport(t *testing.T) {
+	up, down := 60.0, 80.0
+	plugin := Plugin{Config: Config{CoverageThreshold: 75, CoverageFail: true}}
+
+	r := plugin.coverageReport(Coverage{Covered: 7, Total: 10}, &up)
+	assert.Equal(t, "70.0% (▲ +10.0%)\nbelow the threshold of 75.0%", r.Fields[0].Value)
+	assert.Equal(t, ReportFailure, r.Status)
+	assert.EqualError(t, r.Err, "coverage 70.0% is below the threshold of 75.0%")
+
+	r = plugin.coverageReport(Coverage{Covered: 78, Total: 100}, &down)
+	assert.Equal(t, "78.0% (▼ -2.0%)", r.Fields[0].Value)
+	assert.Equal(t, ReportWarning, r.Status)
+	assert.NoError(t, r.Err)
+
+	r = plugin.coverageReport(Coverage{Covered: 8, Total: 10}, &down)
+	assert.Equal(t, "80.0% (= 0.0%)", r.Fields[0].Value)
+	assert.Equal(t, ReportSuccess, r.Status)
+}
+
+func TestExecWithCoverage(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	plugin := Plugin{
+		Build: Build{Status: "success"},
+		Config: Config{
+			webhookURL:        srv.URL + "/api/webhooks/1/token",
+			AllowedHosts:      testHosts,
+			Coverage:          []string{writeCoverage(t, "cover.out", coverProfile)},
+			CoverageBaseline:  "72.5",
+			CoverageThreshold: 80,
+			CoverageFail:      true,
+		},
+	}
+	assert.EqualError(t, plugin.Exec(context.Background()), "coverage 70.0% is below the threshold of 80.0%")
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 1)
+	assert.Contains(t, bodies[0], `{"name":"Coverage","value":"70.0% (▼ -2.5%)\nbelow the threshold of 80.0%"}`)
+	assert.Contains(t, bodies[0], `"color":16724530`)
+}
+
+func TestExecSkipsBrokenCoverage(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	plugin := Plugin{
+		Build: Build{Status: "success"},
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/token",
+			AllowedHosts: testHosts,
+			Coverage: []string{
+				writeCoverage(t, "cover.out", coverProfile),
+				writeCoverage(t, "coverage.txt", "not a coverage report"),
+			},
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 1)
+	assert.Contains(t, bodies[0], `{"name":"Coverage","value":"70.0%"}`)
+}
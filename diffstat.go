@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+)
+
+type (
+	// DiffStat summarizes the changes of the push.
+	DiffStat struct {
+		Files       int
+		Insertions  int
+		Deletions   int
+		Directories []DirectoryStat
+	}
+
+	// DirectoryStat counts the changed files of a top-level directory.
+	DirectoryStat struct {
+		Name  string
+		Files int
+	}
+)
+
+// topDirectories returns the n top-level directories with the most changed
+// files. Files in the repository root are counted as ".".
+func topDirectories(files []string, n int) []DirectoryStat {
+	counts := map[string]int{}
+	for _, f := range files {
+		dir := "."
+		if i := strings.IndexByte(f, '/'); i >= 0 {
+			dir = f[:i]
+		}
+		counts[dir]++
+	}
+
+	dirs := make([]DirectoryStat, 0, len(counts))
+	for name, files := range counts {
+		dirs = append(dirs, DirectoryStat{Name: name, Files: files})
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if dirs[i].Files != dirs[j].Files {
+			return dirs[i].Files > dirs[j].Files
+		}
+		return dirs[i].Name < dirs[j].Name
+	})
+	if len(dirs) > n {
+		dirs = dirs[:n]
+	}
+	return dirs
+}
+
+// loadDiffStat reads the diffstat of the push when enabled.
+func (p *Plugin) loadDiffStat(ctx context.Context) {
+	if !p.Config.Diffstat || p.Commit.Stat.Files > 0 {
+		return
+	}
+	if err := p.loadDiff(ctx); err != nil && p.Config.Debug {
+		log.Printf("failed to read diffstat: %v", err)
+	}
+}
+
+// diffStatFields renders the diffstat as an embed field.
+func (p *Plugin) diffStatFields() []EmbedFieldObject {
+	stat := p.Commit.Stat
+	if !p.Config.Diffstat || stat.Files == 0 {
+		return nil
+	}
+
+	value := p.tr("diffstat", stat.Files, stat.Insertions, stat.Deletions)
+	dirs := make([]string, 0, len(stat.Directories))
+	for _, d := range stat.Directories {
+		name := d.Name
+		if name != "." {
+			name += "/"
+		}
+		dirs = append(dirs, fmt.Sprintf("`%s` (%d)", name, d.Files))
+	}
+	if len(dirs) > 0 {
+		value += "\n" + strings.Join(dirs, ", ")
+	}
+
+	return []EmbedFieldObject{{
+		Name:  p.tr("changes"),
+		Value: value,
+	}}
+}
+
+// onlyIgnoredPaths reports whether every changed file matches the ignore
+// globs, in which case the notification is skipped.
+func (p *Plugin) onlyIgnoredPaths(ctx context.Context) bool {
+	if len(p.Config.IgnorePaths) == 0 {
+		return false
+	}
+
+	files, err := p.changedFiles(ctx)
+	if err != nil {
+		if p.Config.Debug {
+			log.Printf("failed to list changed files: %v", err)
+		}
+		return false
+	}
+	if len(files) == 0 {
+		return false
+	}
+
+	for _, f := range files {
+		if !matchAny(p.Config.IgnorePaths, f, true) && !matchAny(p.Config.IgnorePaths, path.Base(f), true) {
+			return false
+		}
+	}
+	log.Printf("only ignored paths changed, skipping notification")
+	return true
+}
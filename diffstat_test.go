@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopDirectories(t *testing.T) {
+	dirs := topDirectories([]string{"src/a.go", "src/b.go", "docs/index.md", "README.md", "src/c/d.go", "api/x.go"}, 2)
+	assert.Equal(t, []DirectoryStat{{Name: "src", Files: 3}, {Name: ".", Files: 1}}, dirs)
+}
+
+func TestDiffStatFields(t *testing.T) {
+	repo := newGitRepo(t)
+	before := repo.commit("initial", map[string]string{"src/a.go": "a\nb\nc\n"})
+	after := repo.commit("change", map[string]string{
+		"src/a.go":      "a\nc\nd\ne\n",
+		"src/b.go":      "b\n",
+		"docs/index.md": "docs\n",
+	})
+
+	p := Plugin{
+		Commit: Commit{Before: before, After: after},
+		Config: Config{Workspace: repo.dir, Diffstat: true},
+	}
+	p.loadDiffStat(context.Background())
+	assert.Equal(t, 3, p.Commit.Stat.Files)
+	assert.Equal(t, 4, p.Commit.Stat.Insertions)
+	assert.Equal(t, 1, p.Commit.Stat.Deletions)
+
+	fields := p.diffStatFields()
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "Changes", fields[0].Name)
+	assert.Equal(t, "3 files changed, 4 insertions(+), 1 deletions(-)\n`src/` (2), `docs/` (1)", fields[0].Value)
+}
+
+func TestIgnorePaths(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	repo := newGitRepo(t)
+	before := repo.commit("initial", map[string]string{"main.go": "package main"})
+	docs := repo.commit("docs", map[string]string{"README.md": "hello", "docs/guide/index.md": "guide"})
+
+	newPlugin := func() Plugin {
+		return Plugin{
+			Commit: Commit{Before: before, After: docs},
+			Config: Config{
+				webhookURL:   srv.URL + "/api/webhooks/1/token",
+				AllowedHosts: testHosts,
+				Workspace:    repo.dir,
+				IgnorePaths:  []string{"*.md", "docs/**"},
+				Message:      []string{"hello"},
+			},
+		}
+	}
+
+	plugin := newPlugin()
+	assert.NoError(t, plugin.Exec(context.Background()))
+	paths, _ := rec.requests()
+	assert.Empty(t, paths)
+
+	code := repo.commit("code", map[string]string{"main.go": "package main\n"})
+	plugin = newPlugin()
+	plugin.Commit.After = code
+	assert.NoError(t, plugin.Exec(context.Background()))
+	paths, _ = rec.requests()
+	assert.Len(t, paths, 1)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// stdout receives the requests printed in dry-run mode.
+var stdout io.Writer = os.Stdout
+
+// dryRunTransport prints the requests instead of sending them, answering
+// every request with 204 No Content.
+type dryRunTransport struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s %s\n", req.Method, redactURL(req.URL.String()))
+	contentType := req.Header.Get("Content-Type")
+	if contentType != "" {
+		fmt.Fprintf(&out, "Content-Type: %s\n", contentType)
+	}
+	fmt.Fprintln(&out)
+
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if mediaType == "multipart/form-data" {
+		if err := writeManifest(&out, body, params["boundary"]); err != nil {
+			return nil, fmt.Errorf("failed to read multipart body: %w", err)
+		}
+	} else if len(body) > 0 {
+		out.Write(body)
+		if !bytes.HasSuffix(body, []byte("\n")) {
+			fmt.Fprintln(&out)
+		}
+	}
+	fmt.Fprintln(&out)
+
+	t.mu.Lock()
+	_, err := t.w.Write(out.Bytes())
+	t.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:     "204 No Content",
+		StatusCode: http.StatusNoContent,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
+// writeManifest lists the fields and files of a multipart body.
+func writeManifest(w io.Writer, body []byte, boundary string) error {
+	r := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := r.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return err
+		}
+		if part.FileName() == "" {
+			fmt.Fprintf(w, "field %s: %s\n", part.FormName(), strings.TrimSpace(string(content)))
+			continue
+		}
+		fmt.Fprintf(w, "file %s: %s (%d bytes, %s)\n",
+			part.FormName(), part.FileName(), len(content), part.Header.Get("Content-Type"))
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecDryRun(t *testing.T) {
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "report.txt")
+	assert.NoError(t, os.WriteFile(file, []byte("report"), 0o600))
+	stateFile := filepath.Join(dir, "state")
+
+	plugin := Plugin{
+		Build: Build{Number: 5, Status: "success"},
+		Config: Config{
+			WebhookID:    "1",
+			WebhookToken: "secret-token",
+			Message:      []string{"build {{build.number}}", strings.Repeat("x", 2500)},
+			Mode:         ModePlain,
+			File:         []string{file},
+			StateFile:    stateFile,
+			DryRun:       true,
+		},
+		Payload: Payload{Username: "drone"},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	got := out.String()
+	assert.NotContains(t, got, "secret-token")
+	assert.Equal(t, 4, strings.Count(got, "POST https://discord.com/api/webhooks/1/***\n"))
+	assert.Contains(t, got, "Content-Type: application/json; charset=utf-8\n\n"+`{"wait":false,"content":"build 5",`)
+	assert.Contains(t, got, `"content":"`+strings.Repeat("x", 2000)+`"`)
+	assert.Contains(t, got, `"content":"`+strings.Repeat("x", 500)+`"`)
+	assert.Contains(t, got, "file file: report.txt (6 bytes, application/octet-stream)\nfield username: drone\n")
+
+	_, err := os.Stat(stateFile)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestExecDryRunPrintsWebhooksInOrder(t *testing.T) {
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+
+	plugin := Plugin{
+		Build: Build{Number: 5, Status: "success"},
+		Config: Config{
+			WebhookID:    "1",
+			WebhookToken: "token",
+			Webhooks: []Webhook{
+				{URL: "https://discord.com/api/webhooks/2/token"},
+				{URL: "https://discord.com/api/webhooks/3/token"},
+				{URL: "https://discord.com/api/webhooks/4/token"},
+			},
+			MaxConcurrency: 4,
+			DryRun:         true,
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	got := out.String()
+	last := -1
+	for _, id := range []string{"1", "2", "3", "4"} {
+		i := strings.Index(got, "POST https://discord.com/api/webhooks/"+id+"/***\n")
+		assert.Greater(t, i, last, "webhook %s", id)
+		last = i
+	}
+}
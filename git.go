@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// zeroSHA is reported as the previous commit of a newly created branch.
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// git runs a git command in the workspace and returns its trimmed output.
+func (p *Plugin) git(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = p.Config.Workspace
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// commitRange returns the before and after commits of the push. The before
+// commit is empty when it is unknown or the branch was just created.
+func (p *Plugin) commitRange() (string, string) {
+	before := p.Commit.Before
+	if before == zeroSHA {
+		before = ""
+	}
+	after := p.Commit.After
+	if after == "" {
+		after = p.Commit.Sha
+	}
+	if after == "" {
+		after = "HEAD"
+	}
+	return before, after
+}
+
+// commitLog lists the commits reachable from after but not from before,
+// newest first. Without before, all commits reachable from after are listed.
+func (p *Plugin) commitLog(ctx context.Context, before, after string) ([]CommitEntry, error) {
+	rev := after
+	if before != "" {
+		rev = before + ".." + after
+	}
+	out, err := p.git(ctx, "log", "--format=%H%x1f%h%x1f%an%x1f%s%x1f%b%x1e", rev)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []CommitEntry{}
+	for _, record := range strings.Split(out, "\x1e") {
+		parts := strings.Split(strings.TrimSpace(record), "\x1f")
+		if len(parts) != 5 {
+			continue
+		}
+		entries = append(entries, CommitEntry{
+			Sha:      parts[0],
+			ShortSha: parts[1],
+			Author:   parts[2],
+			Subject:  parts[3],
+			Body:     strings.TrimSpace(parts[4]),
+		})
+	}
+	return entries, nil
+}
+
+// loadCommits lists the commits of the push from the local git checkout.
+// Without a checkout or a previous commit the list stays empty.
+func (p *Plugin) loadCommits(ctx context.Context) {
+	if p.Config.MaxCommits <= 0 || p.Build.Event != "push" || p.Commit.List != nil {
+		return
+	}
+
+	before, after := p.commitRange()
+	if before == "" {
+		return
+	}
+
+	entries, err := p.commitLog(ctx, before, after)
+	if err != nil {
+		if p.Config.Debug {
+			log.Printf("failed to list commits: %v", err)
+		}
+		return
+	}
+	p.Commit.List = entries
+}
+
+// commitFields renders the commits of the push as embed fields, linking to
+// the compare view for the commits left out.
+func (p *Plugin) commitFields() []EmbedFieldObject {
+	entries := p.Commit.List
+	if len(entries) <= 1 {
+		return nil
+	}
+
+	// Leave room for the "more" field and the build time fields.
+	limit := min(p.Config.MaxCommits, len(entries), maxFields-4)
+	fields := make([]EmbedFieldObject, 0, limit+1)
+	for _, c := range entries[:limit] {
+		fields = append(fields, EmbedFieldObject{
+			Name:  fmt.Sprintf("`%s` %s", c.ShortSha, c.Author),
+			Value: c.Subject,
+		})
+	}
+
+	if more := len(entries) - limit; more > 0 {
+		value := p.tr("more_commits", more)
+		if p.Commit.Link != "" {
+			value = string(maskedLink(value, p.Commit.Link))
+		}
+		fields = append(fields, EmbedFieldObject{
+			Name:  "…",
+			Value: value,
+		})
+	}
+	return fields
+}
+
+// changedFiles lists the files changed by the push using the local git
+// checkout. The result is cached for subsequent calls.
+func (p *Plugin) changedFiles(ctx context.Context) ([]string, error) {
+	if p.Commit.ChangedFiles != nil {
+		return p.Commit.ChangedFiles, nil
+	}
+	if err := p.loadDiff(ctx); err != nil {
+		return nil, err
+	}
+	return p.Commit.ChangedFiles, nil
+}
+
+// loadDiff reads the changed files and line counts of the push.
+func (p *Plugin) loadDiff(ctx context.Context) error {
+	before, after := p.commitRange()
+	args := []string{"diff-tree", "--no-commit-id", "--numstat", "--no-renames", "-r", after}
+	if before != "" {
+		args = []string{"diff", "--numstat", "--no-renames", before, after}
+	}
+
+	out, err := p.git(ctx, args...)
+	if err != nil {
+		return err
+	}
+
+	files := []string{}
+	stat := DiffStat{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		// Binary files report "-" instead of line counts.
+		added, _ := strconv.Atoi(parts[0])
+		deleted, _ := strconv.Atoi(parts[1])
+		stat.Insertions += added
+		stat.Deletions += deleted
+		files = append(files, parts[2])
+	}
+	stat.Files = len(files)
+	stat.Directories = topDirectories(files, 3)
+
+	p.Commit.ChangedFiles = files
+	p.Commit.Stat = stat
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGitRepo creates a git repository in a temporary directory.
+func newGitRepo(t *testing.T) *gitRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	r := &gitRepo{t: t, dir: t.TempDir()}
+	r.run("init", "-q", "-b", "main")
+	r.run("config", "user.name", "appleboy")
+	r.run("config", "user.email", "appleboy@example.com")
+	r.run("config", "commit.gpgsign", "false")
+	r.run("config", "tag.gpgsign", "false")
+	return r
+}
+
+type gitRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func (r *gitRepo) run(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes the files and commits them, returning the commit SHA.
+func (r *gitRepo) commit(message string, files map[string]string) string {
+	r.t.Helper()
+	for name, content := range files {
+		path := filepath.Join(r.dir, name)
+		require.NoError(r.t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(r.t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	r.run("add", "-A")
+	r.run("commit", "-q", "--allow-empty", "-m", message)
+	return r.run("rev-parse", "HEAD")
+}
+
+func TestChangedFiles(t *testing.T) {
+	repo := newGitRepo(t)
+	before := repo.commit("initial", map[string]string{"README.md": "hello"})
+	repo.commit("docs", map[string]string{"docs/index.md": "docs"})
+	after := repo.commit("code", map[string]string{"main.go": "package main"})
+
+	p := Plugin{
+		Commit: Commit{Before: before, After: after},
+		Config: Config{Workspace: repo.dir},
+	}
+	files, err := p.changedFiles(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs/index.md", "main.go"}, files)
+
+	p = Plugin{
+		Commit: Commit{Before: zeroSHA, Sha: after},
+		Config: Config{Workspace: repo.dir},
+	}
+	files, err = p.changedFiles(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, files)
+}
+
+func TestCommitFields(t *testing.T) {
+	repo := newGitRepo(t)
+	before := repo.commit("initial", nil)
+	for i := 1; i <= 4; i++ {
+		repo.commit(fmt.Sprintf("feat: change %d", i), nil)
+	}
+
+	p := Plugin{
+		Commit: Commit{Before: before, Link: "https://github.com/appleboy/go-hello/compare/a...b"},
+		Build:  Build{Event: "push"},
+		Config: Config{Workspace: repo.dir, MaxCommits: 3},
+	}
+	p.loadCommits(context.Background())
+	assert.Len(t, p.Commit.List, 4)
+	assert.Equal(t, "feat: change 4", p.Commit.List[0].Subject)
+
+	fields := p.Template().Fields
+	assert.Len(t, fields, 4)
+	assert.Equal(t, "`"+p.Commit.List[0].ShortSha+"` appleboy", fields[0].Name)
+	assert.Equal(t, "feat: change 4", fields[0].Value)
+	assert.Equal(t, "[and 1 more commits](https://github.com/appleboy/go-hello/compare/a...b)", fields[3].Value)
+}
+
+func TestLoadCommitsWithoutCheckout(t *testing.T) {
+	p := Plugin{
+		Commit: Commit{Before: "abc", After: "def"},
+		Build:  Build{Event: "push"},
+		Config: Config{Workspace: t.TempDir(), MaxCommits: 10},
+	}
+	p.loadCommits(context.Background())
+	assert.Nil(t, p.Commit.List)
+	assert.Empty(t, p.Template().Fields)
+}
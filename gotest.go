@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	// goTestEvent is a line of `go test -json` output.
+	goTestEvent struct {
+		Action  string
+		Package string
+		Test    string
+		Output  string
+		Elapsed float64
+	}
+
+	// GoTestSummary counts the package and test results of `go test -json`
+	// output.
+	GoTestSummary struct {
+		Packages TestSummary
+		Tests    TestSummary
+		Failures []GoTestFailure
+	}
+
+	// GoTestFailure is a failed test or package with its output.
+	GoTestFailure struct {
+		Package string
+		Test    string
+		Output  []string
+	}
+)
+
+// Name returns the package qualified name of the failed test.
+func (f GoTestFailure) Name() string {
+	if f.Test == "" {
+		return f.Package
+	}
+	return f.Package + "." + f.Test
+}
+
+// parseGoTest reads the results of a `go test -json` file.
+func parseGoTest(path string) (GoTestSummary, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return GoTestSummary{}, err
+	}
+	defer f.Close()
+
+	var s GoTestSummary
+	output := map[[2]string][]string{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		b := scanner.Bytes()
+		if len(b) == 0 || b[0] != '{' {
+			continue
+		}
+
+		var e goTestEvent
+		if err := json.Unmarshal(b, &e); err != nil {
+			return GoTestSummary{}, fmt.Errorf("invalid go test output %s on line %d: %w", path, line, err)
+		}
+
+		key := [2]string{e.Package, e.Test}
+		counts := &s.Tests
+		if e.Test == "" {
+			counts = &s.Packages
+		}
+		switch e.Action {
+		case "output":
+			output[key] = append(output[key], strings.TrimRight(e.Output, "\n"))
+		case "pass":
+			counts.Passed++
+		case "skip":
+			counts.Skipped++
+		case "fail":
+			counts.Failed++
+			s.Failures = append(s.Failures, GoTestFailure{
+				Package: e.Package,
+				Test:    e.Test,
+				Output:  output[key],
+			})
+		}
+		if e.Test == "" && e.Action != "output" {
+			s.Packages.Time += time.Duration(e.Elapsed * float64(time.Second))
+		}
+		if e.Action != "output" && e.Action != "run" {
+			delete(output, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return GoTestSummary{}, fmt.Errorf("failed to read go test output %s: %w", path, err)
+	}
+
+	s.Tests.Time = s.Packages.Time
+	s.Failures = failedTests(s.Failures)
+	return s, nil
+}
+
+// failedTests drops failed packages and parent tests whose failure is
+// explained by a failed test they contain.
+func failedTests(failures []GoTestFailure) []GoTestFailure {
+	var result []GoTestFailure
+	for _, f := range failures {
+		explained := false
+		for _, other := range failures {
+			if other.Package == f.Package && other.Test != f.Test &&
+				(f.Test == "" || strings.HasPrefix(other.Test, f.Test+"/")) {
+				explained = true
+				break
+			}
+		}
+		if !explained {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// goTestOutputLength is the share of the embed length available to the
+// output of all failures, leaving the rest for the other fields.
+const goTestOutputLength = maxEmbedLength / 2
+
+// tailLines returns the last n lines of the output that fit into size
+// runes once escaped for a code block.
+func tailLines(lines []string, n, size int) string {
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	join := func(lines []string) string {
+		return strings.ReplaceAll(strings.Join(lines, "\n"), "```", "`\u200b``")
+	}
+	out := join(lines)
+	for len(lines) > 1 && utf8.RuneCountInString(out) > size {
+		lines = lines[1:]
+		out = join(lines)
+	}
+	return truncateText(out, size)
+}
+
+// goTestReport renders the summary as embed fields with the output of the
+// first failures up to the limit.
+func (p *Plugin) goTestReport(s GoTestSummary, limit, lines int) report {
+	r := report{Status: ReportSuccess}
+	if s.Packages.Failed > 0 || s.Tests.Failed > 0 {
+		r.Status = ReportFailure
+	}
+
+	r.Fields = append(r.Fields,
+		EmbedFieldObject{
+			Name:  p.tr("packages"),
+			Value: p.tr("package_summary", s.Packages.Passed, s.Packages.Failed, s.Packages.Skipped),
+		},
+		EmbedFieldObject{
+			Name:  p.tr("tests"),
+			Value: p.tr("test_summary", s.Tests.Passed, s.Tests.Failed, s.Tests.Skipped, s.Tests.Time.Round(time.Millisecond)),
+		},
+	)
+
+	// the failures share the output length evenly
+	share := maxFieldValueLength
+	if n := min(len(s.Failures), limit); n > 0 {
+		share = min(share, goTestOutputLength/n)
+	}
+
+	for i, f := range s.Failures {
+		if i == limit {
+			r.Fields = append(r.Fields, EmbedFieldObject{
+				Name:  p.tr("failed_tests"),
+				Value: p.tr("more_failures", len(s.Failures)-limit),
+			})
+			break
+		}
+		value := "-"
+		// leave room for the name and the code block fences
+		size := share - utf8.RuneCountInString(f.Name()) - len("```\n\n```")
+		if lines > 0 && len(f.Output) > 0 && size > 0 {
+			value = string(codeBlock("", tailLines(f.Output, lines, size)))
+		}
+		r.Fields = append(r.Fields, EmbedFieldObject{
+			Name:  f.Name(),
+			Value: value,
+		})
+	}
+	return r
+}
+
+// loadGoTest summarizes the `go test -json` files matching the configured
+// globs.
+func (p *Plugin) loadGoTest() error {
+	if len(p.Config.GoTest) == 0 {
+		return nil
+	}
+
+	files, err := expandGlobs(p.Config.GoTest)
+	if err != nil {
+		return fmt.Errorf("invalid go test pattern: %w", err)
+	}
+	if len(files) == 0 {
+		log.Printf("no go test output found for %s", strings.Join(p.Config.GoTest, ", "))
+		return nil
+	}
+
+	// a crashed test run may leave broken output, which must not stop the
+	// notification
+	var (
+		total  GoTestSummary
+		parsed int
+	)
+	for _, f := range files {
+		s, err := parseGoTest(f)
+		if err != nil {
+			log.Printf("skipping go test output: %v", err)
+			continue
+		}
+		total.Packages.merge(s.Packages)
+		total.Tests.merge(s.Tests)
+		total.Failures = append(total.Failures, s.Failures...)
+		parsed++
+	}
+	if parsed == 0 {
+		return nil
+	}
+
+	r := p.goTestReport(total, p.Config.GoTestMaxFailures, p.Config.GoTestLines)
+	r.Files = files
+	p.addReport(r, p.Config.GoTestAttach)
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const goTestOutput = `{"Action":"start","Package":"example.com/api"}
+{"Action":"run","Package":"example.com/api","Test":"TestUsers"}
+{"Action":"output","Package":"example.com/api","Test":"TestUsers","Output":"=== RUN   TestUsers\n"}
+{"Action":"run","Package":"example.com/api","Test":"TestUsers/create"}
+{"Action":"output","Package":"example.com/api","Test":"TestUsers/create","Output":"=== RUN   TestUsers/create\n"}
+{"Action":"output","Package":"example.com/api","Test":"TestUsers/create","Output":"    users_test.go:12: expected 201\n"}
+{"Action":"output","Package":"example.com/api","Test":"TestUsers/create","Output":"    users_test.go:13: got 500\n"}
+{"Action":"fail","Package":"example.com/api","Test":"TestUsers/create","Elapsed":0.1}
+{"Action":"fail","Package":"example.com/api","Test":"TestUsers","Elapsed":0.1}
+{"Action":"run","Package":"example.com/api","Test":"TestHealth"}
+{"Action":"pass","Package":"example.com/api","Test":"TestHealth","Elapsed":0}
+{"Action":"output","Package":"example.com/api","Output":"FAIL\n"}
+{"Action":"fail","Package":"example.com/api","Elapsed":1.5}
+{"Action":"run","Package":"example.com/db","Test":"TestMigrate"}
+{"Action":"skip","Package":"example.com/db","Test":"TestMigrate","Elapsed":0}
+{"Action":"pass","Package":"example.com/db","Elapsed":0.5}
+{"Action":"output","Package":"example.com/cmd","Output":"?   \texample.com/cmd\t[no test files]\n"}
+{"Action":"skip","Package":"example.com/cmd","Elapsed":0}
+`
+
+func TestParseGoTest(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.json")
+	assert.NoError(t, os.WriteFile(file, []byte(goTestOutput), 0o600))
+
+	s, err := parseGoTest(file)
+	assert.NoError(t, err)
+	assert.Equal(t, TestSummary{Passed: 1, Failed: 1, Skipped: 1, Time: 2 * time.Second}, s.Packages)
+	assert.Equal(t, 1, s.Tests.Passed)
+	assert.Equal(t, 2, s.Tests.Failed)
+	assert.Equal(t, 1, s.Tests.Skipped)
+	assert.Equal(t, []GoTestFailure{{
+		Package: "example.com/api",
+		Test:    "TestUsers/create",
+		Output: []string{
+			"=== RUN   TestUsers/create",
+			"    users_test.go:12: expected 201",
+			"    users_test.go:13: got 500",
+		},
+	}}, s.Failures)
+}
+
+func TestParseGoTestInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.json")
+	assert.NoError(t, os.WriteFile(file, []byte("ok\n{\"Action\":\n"), 0o600))
+
+	_, err := parseGoTest(file)
+	assert.ErrorContains(t, err, "on line 2")
+}
+
+func TestTailLines(t *testing.T) {
+	assert.Equal(t, "c\nd", tailLines([]string{"a", "b", "c", "d"}, 2, 100))
+	assert.Equal(t, "`\u200b``", tailLines([]string{"```"}, 1, 100))
+
+	long := strings.Repeat("x", 600)
+	assert.Equal(t, long, tailLines([]string{long, long}, 2, 1000))
+	assert.Equal(t, strings.Repeat("x", 99)+"…", tailLines([]string{long}, 1, 100))
+}
+
+func TestExecSkipsBrokenGoTest(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(goTestOutput), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"Action":"run","Package":`), 0o600))
+
+	plugin := Plugin{
+		Build: Build{Status: "failure"},
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/token",
+			AllowedHosts: testHosts,
+			GoTest:       []string{filepath.Join(dir, "*.json")},
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 1)
+	assert.Contains(t, bodies[0], `{"name":"Packages","value":"1 passed, 1 failed, 1 skipped"}`)
+}
+
+func TestGoTestReportLength(t *testing.T) {
+	var s GoTestSummary
+	for i := 0; i < 8; i++ {
+		f := GoTestFailure{Package: "example.com/api", Test: fmt.Sprintf("TestUsers/case_%d", i)}
+		for j := 0; j < 10; j++ {
+			f.Output = append(f.Output, strings.Repeat("y", 200))
+		}
+		s.Failures = append(s.Failures, f)
+	}
+
+	var p Plugin
+	r := p.goTestReport(s, 5, 10)
+	assert.Len(t, r.Fields, 8)
+
+	total := 0
+	for _, f := range r.Fields[2:7] {
+		assert.True(t, strings.HasPrefix(f.Value, "```\n"))
+		assert.True(t, strings.HasSuffix(f.Value, "\n```"))
+		assert.LessOrEqual(t, utf8.RuneCountInString(f.Value), maxFieldValueLength)
+		total += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	assert.LessOrEqual(t, total, goTestOutputLength)
+
+	e := limitEmbed(EmbedObject{Fields: r.Fields})
+	assert.Len(t, e.Fields, len(r.Fields))
+}
+
+func TestExecWithGoTest(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+	file := filepath.Join(t.TempDir(), "test.json")
+	assert.NoError(t, os.WriteFile(file, []byte(goTestOutput), 0o600))
+
+	plugin := Plugin{
+		Build: Build{Status: "success"},
+		Config: Config{
+			webhookURL:        srv.URL + "/api/webhooks/1/token",
+			AllowedHosts:      testHosts,
+			GoTest:            []string{file},
+			GoTestMaxFailures: 5,
+			GoTestLines:       2,
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+	assert.Equal(t, 0xff3232, plugin.Color())
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 1)
+	assert.Contains(t, bodies[0], `{"name":"Packages","value":"1 passed, 1 failed, 1 skipped"}`)
+	assert.Contains(t, bodies[0], `{"name":"example.com/api.TestUsers/create","value":"`+"```"+`\n    users_test.go:12: expected 201\n    users_test.go:13: got 500\n`+"```"+`"}`)
+}
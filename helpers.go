@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mailgun/raymond/v2"
+)
+
+// helpers are the Discord specific template helpers, registered alongside
+// the drone-template-lib helpers. Helpers producing Discord markup return
+// a raymond.SafeString so handlebars does not HTML-escape it.
+var helpers = map[string]interface{}{
+	"discordTime":    discordTime,
+	"escapeMarkdown": escapeMarkdown,
+	"codeBlock":      codeBlock,
+	"maskedLink":     maskedLink,
+	"shortSha":       shortSHA,
+	"firstLine":      firstLine,
+	"mentionUser":    mentionUser,
+	"mentionRole":    mentionRole,
+	"mentionChannel": mentionChannel,
+	"statusEmoji":    statusEmoji,
+}
+
+func init() {
+	raymond.RegisterHelpers(helpers)
+}
+
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"~", `\~`,
+	"`", "\\`",
+	"|", `\|`,
+	">", `\>`,
+	"#", `\#`,
+	"[", `\[`,
+	"]", `\]`,
+	"(", `\(`,
+	")", `\)`,
+)
+
+// discordTime formats a unix timestamp with Discord timestamp markup, which
+// every client renders in its own timezone and language. The style is
+// required, as helpers take a fixed number of arguments, and is one of t,
+// T, d, D, f, F or R (relative).
+func discordTime(timestamp int64, style string) raymond.SafeString {
+	return raymond.SafeString(fmt.Sprintf("<t:%d:%s>", timestamp, style))
+}
+
+// escapeMarkdown escapes Discord markdown so the text is shown as is.
+func escapeMarkdown(s string) raymond.SafeString {
+	return raymond.SafeString(markdownReplacer.Replace(s))
+}
+
+// codeBlock wraps the content in a code block with a language hint.
+func codeBlock(lang, content string) raymond.SafeString {
+	content = strings.ReplaceAll(content, "```", "`\u200b``")
+	return raymond.SafeString("```" + lang + "\n" + strings.TrimRight(content, "\n") + "\n```")
+}
+
+// maskedLink returns a markdown link showing the text.
+func maskedLink(text, url string) raymond.SafeString {
+	return raymond.SafeString("[" + strings.NewReplacer("[", `\[`, "]", `\]`).Replace(text) + "](" + url + ")")
+}
+
+// shortSHA returns the abbreviated commit SHA.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// firstLine returns the first line of the text, usually the commit subject.
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// mentionUser returns the mention syntax for the user ID.
+func mentionUser(id string) raymond.SafeString {
+	return raymond.SafeString("<@" + id + ">")
+}
+
+// mentionRole returns the mention syntax for the role ID.
+func mentionRole(id string) raymond.SafeString {
+	return raymond.SafeString("<@&" + id + ">")
+}
+
+// mentionChannel returns the mention syntax for the channel ID.
+func mentionChannel(id string) raymond.SafeString {
+	return raymond.SafeString("<#" + id + ">")
+}
+
+// statusEmoji returns an emoji for the build status.
+func statusEmoji(status string) string {
+	switch status {
+	case "success":
+		return "✅"
+	case "failure", "error":
+		return "❌"
+	case "killed":
+		return "🛑"
+	case "running", "pending", "started":
+		return "⏳"
+	default:
+		return "❔"
+	}
+}
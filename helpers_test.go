@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelpers(t *testing.T) {
+	assert.Equal(t, "<t:1714557600:R>", string(discordTime(1714557600, "R")))
+	assert.Equal(t, `fix \*bold\* \_under\_ \[link\]`, string(escapeMarkdown("fix *bold* _under_ [link]")))
+	assert.Equal(t, "```go\nfmt.Println()\n```", string(codeBlock("go", "fmt.Println()\n")))
+	assert.Equal(t, "```\n`\u200b``\n```", string(codeBlock("", "```")))
+	assert.Equal(t, `[\[v1\]](https://example.com)`, string(maskedLink("[v1]", "https://example.com")))
+	assert.Equal(t, "e5e82b5", shortSHA("e5e82b5eb3737205c25955dcc3dcacc839b7be52"))
+	assert.Equal(t, "abc", shortSHA("abc"))
+	assert.Equal(t, "feat: add helpers", firstLine("feat: add helpers\n\nlong description"))
+	assert.Equal(t, "<@123>", string(mentionUser("123")))
+	assert.Equal(t, "<@&123>", string(mentionRole("123")))
+	assert.Equal(t, "<#123>", string(mentionChannel("123")))
+	assert.Equal(t, "✅", statusEmoji("success"))
+	assert.Equal(t, "❌", statusEmoji("failure"))
+	assert.Equal(t, "❔", statusEmoji("unknown"))
+}
+
+func TestHelpersInTemplate(t *testing.T) {
+	p := Plugin{
+		Commit: Commit{Sha: "e5e82b5eb3737205c25955dcc3dcacc839b7be52", Message: "feat: *new*\n\nbody", Link: "https://example.com"},
+		Build:  Build{Status: "success", Finished: 1714557600},
+	}
+
+	txt, err := templateMessage(`{{statusEmoji build.status}} {{maskedLink (shortSha commit.sha) commit.link}} {{escapeMarkdown (firstLine commit.message)}} {{discordTime build.finished "R"}}`, p)
+	assert.NoError(t, err)
+	assert.Equal(t, `✅ [e5e82b5](https://example.com) feat: \*new\* <t:1714557600:R>`, txt)
+}
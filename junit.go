@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// junitSuite is a <testsuites> or <testsuite> element.
+	junitSuite struct {
+		Name   string       `xml:"name,attr"`
+		Suites []junitSuite `xml:"testsuite"`
+		Cases  []junitCase  `xml:"testcase"`
+	}
+
+	junitCase struct {
+		Name      string       `xml:"name,attr"`
+		Classname string       `xml:"classname,attr"`
+		Time      string       `xml:"time,attr"`
+		Failure   *junitResult `xml:"failure"`
+		Error     *junitResult `xml:"error"`
+		Skipped   *junitResult `xml:"skipped"`
+	}
+
+	junitResult struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+
+	// TestSummary counts the test results of a report.
+	TestSummary struct {
+		Passed   int
+		Failed   int
+		Skipped  int
+		Time     time.Duration
+		Failures []TestFailure
+	}
+
+	// TestFailure is a failed test with its message.
+	TestFailure struct {
+		Name    string
+		Message string
+	}
+)
+
+// add counts the test cases of the suite and its nested suites.
+func (s *TestSummary) add(suite junitSuite) {
+	for _, c := range suite.Cases {
+		if secs, err := strconv.ParseFloat(strings.ReplaceAll(c.Time, ",", ""), 64); err == nil {
+			s.Time += time.Duration(secs * float64(time.Second))
+		}
+
+		result := c.Failure
+		if result == nil {
+			result = c.Error
+		}
+		switch {
+		case result != nil:
+			s.Failed++
+			name := c.Name
+			if c.Classname != "" {
+				name = c.Classname + "." + c.Name
+			}
+			message := result.Message
+			if message == "" {
+				message = firstLine(result.Text)
+			}
+			s.Failures = append(s.Failures, TestFailure{Name: name, Message: message})
+		case c.Skipped != nil:
+			s.Skipped++
+		default:
+			s.Passed++
+		}
+	}
+	for _, nested := range suite.Suites {
+		s.add(nested)
+	}
+}
+
+// merge adds the counts of another summary.
+func (s *TestSummary) merge(o TestSummary) {
+	s.Passed += o.Passed
+	s.Failed += o.Failed
+	s.Skipped += o.Skipped
+	s.Time += o.Time
+	s.Failures = append(s.Failures, o.Failures...)
+}
+
+// parseJUnit reads the test results of a JUnit XML file.
+func parseJUnit(path string) (TestSummary, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return TestSummary{}, err
+	}
+
+	var suite junitSuite
+	if err := xml.Unmarshal(b, &suite); err != nil {
+		return TestSummary{}, fmt.Errorf("invalid junit report %s: %w", path, err)
+	}
+
+	var s TestSummary
+	s.add(suite)
+	return s, nil
+}
+
+// testReport renders the test summary as embed fields listing the first
+// failures up to the limit.
+func (p *Plugin) testReport(s TestSummary, limit int) report {
+	r := report{Status: ReportSuccess}
+	if s.Failed > 0 {
+		r.Status = ReportFailure
+	}
+
+	r.Fields = append(r.Fields, EmbedFieldObject{
+		Name:  p.tr("tests"),
+		Value: p.tr("test_summary", s.Passed, s.Failed, s.Skipped, s.Time.Round(time.Millisecond)),
+	})
+
+	if len(s.Failures) > 0 && limit > 0 {
+		lines := make([]string, 0, limit+1)
+		for i, f := range s.Failures {
+			if i == limit {
+				lines = append(lines, p.tr("more_failures", len(s.Failures)-limit))
+				break
+			}
+			line := "**" + string(escapeMarkdown(f.Name)) + "**"
+			if f.Message != "" {
+				line += ": " + truncateText(f.Message, 200)
+			}
+			lines = append(lines, line)
+		}
+		r.Fields = append(r.Fields, EmbedFieldObject{
+			Name:  p.tr("failed_tests"),
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+	return r
+}
+
+// loadJUnit summarizes the JUnit XML reports matching the configured globs.
+func (p *Plugin) loadJUnit() error {
+	if len(p.Config.JUnit) == 0 {
+		return nil
+	}
+
+	files, err := expandGlobs(p.Config.JUnit)
+	if err != nil {
+		return fmt.Errorf("invalid junit pattern: %w", err)
+	}
+	if len(files) == 0 {
+		log.Printf("no junit report found for %s", strings.Join(p.Config.JUnit, ", "))
+		return nil
+	}
+
+	// a crashed test run may leave a broken report, which must not stop
+	// the notification
+	var (
+		total  TestSummary
+		parsed int
+	)
+	for _, f := range files {
+		s, err := parseJUnit(f)
+		if err != nil {
+			log.Printf("skipping junit report: %v", err)
+			continue
+		}
+		total.merge(s)
+		parsed++
+	}
+	if parsed == 0 {
+		return nil
+	}
+
+	r := p.testReport(total, p.Config.JUnitMaxFailures)
+	r.Files = files
+	p.addReport(r, p.Config.JUnitAttach)
+	return nil
+}
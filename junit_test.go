@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const junitReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="api">
+    <testcase classname="api.Users" name="TestCreate" time="1.5"/>
+    <testcase classname="api.Users" name="TestDelete" time="0.25">
+      <failure message="expected 204, got 500">stack trace</failure>
+    </testcase>
+    <testcase classname="api.Users" name="TestSkip" time="0">
+      <skipped/>
+    </testcase>
+  </testsuite>
+  <testsuite name="db">
+    <testcase name="TestMigrate" time="0.25">
+      <error>connection refused
+at db.go:12</error>
+    </testcase>
+  </testsuite>
+</testsuites>`
+
+func TestParseJUnit(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "report.xml")
+	assert.NoError(t, os.WriteFile(file, []byte(junitReport), 0o600))
+
+	s, err := parseJUnit(file)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, s.Passed)
+	assert.Equal(t, 2, s.Failed)
+	assert.Equal(t, 1, s.Skipped)
+	assert.Equal(t, 2*time.Second, s.Time)
+	assert.Equal(t, []TestFailure{
+		{Name: "api.Users.TestDelete", Message: "expected 204, got 500"},
+		{Name: "TestMigrate", Message: "connection refused"},
+	}, s.Failures)
+}
+
+func TestExpandGlobs(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"a/report.xml", "a/b/report.xml", "c/other.json"} {
+		path := filepath.Join(dir, f)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, nil, 0o600))
+	}
+
+	files, err := expandGlobs([]string{filepath.Join(dir, "**", "*.xml"), filepath.Join(dir, "a", "*.xml")})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a", "b", "report.xml"), filepath.Join(dir, "a", "report.xml")}, files)
+}
+
+func TestExecWithJUnit(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+	file := filepath.Join(t.TempDir(), "report.xml")
+	assert.NoError(t, os.WriteFile(file, []byte(junitReport), 0o600))
+
+	plugin := Plugin{
+		Build: Build{Status: "success"},
+		Config: Config{
+			webhookURL:       srv.URL + "/api/webhooks/1/token",
+			AllowedHosts:     testHosts,
+			JUnit:            []string{file},
+			JUnitMaxFailures: 1,
+			JUnitAttach:      true,
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+	assert.Equal(t, 0xff3232, plugin.Color())
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 2)
+	assert.Contains(t, bodies[0], `{"name":"Tests","value":"1 passed, 2 failed, 1 skipped in 2s"}`)
+	assert.Contains(t, bodies[0], `{"name":"Failed tests","value":"**api.Users.TestDelete**: expected 204, got 500\nand 1 more"}`)
+	assert.Contains(t, bodies[1], `filename="report.xml"`)
+}
+
+func TestExecSkipsBrokenJUnit(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.xml"), []byte(junitReport), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.xml"), []byte(junitReport[:200]), 0o600))
+
+	plugin := Plugin{
+		Build: Build{Status: "failure"},
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/token",
+			AllowedHosts: testHosts,
+			JUnit:        []string{filepath.Join(dir, "*.xml")},
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 1)
+	assert.Contains(t, bodies[0], `{"name":"Tests","value":"1 passed, 2 failed, 1 skipped in 2s"}`)
+
+	plugin = Plugin{Build: plugin.Build, Config: plugin.Config}
+	plugin.Config.JUnit = []string{filepath.Join(dir, "b.xml")}
+	assert.NoError(t, plugin.Exec(context.Background()))
+	_, bodies = rec.requests()
+	assert.Len(t, bodies, 2)
+	assert.NotContains(t, bodies[1], `"name":"Tests"`)
+}
+
+func TestColorKeepsFailedBuild(t *testing.T) {
+	plugin := Plugin{Build: Build{Status: "failure"}}
+	plugin.addReport(report{Status: ReportSuccess}, false)
+	assert.Equal(t, 0xff3232, plugin.Color())
+
+	plugin.Build.Status = "killed"
+	plugin.addReport(report{Status: ReportWarning}, false)
+	assert.Equal(t, 0xff3232, plugin.Color())
+
+	plugin = Plugin{Build: Build{Status: "success"}}
+	plugin.addReport(report{Status: ReportWarning}, false)
+	assert.Equal(t, 0xffd930, plugin.Color())
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Discord message limits.
+// https://discord.com/developers/docs/resources/message#embed-object-embed-limits
+const (
+	maxContentLength     = 2000
+	maxTitleLength       = 256
+	maxDescriptionLength = 4096
+	maxFields            = 25
+	maxFieldNameLength   = 256
+	maxFieldValueLength  = 1024
+	maxFooterLength      = 2048
+	maxAuthorLength      = 256
+	maxEmbedLength       = 6000
+)
+
+// truncateText shortens the text to at most n runes, marking the cut with
+// an ellipsis.
+func truncateText(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// limitEmbed truncates the embed so it is accepted by Discord.
+func limitEmbed(e EmbedObject) EmbedObject {
+	e.Title = truncateText(e.Title, maxTitleLength)
+	e.Description = truncateText(e.Description, maxDescriptionLength)
+	e.Author.Name = truncateText(e.Author.Name, maxAuthorLength)
+	e.Footer.Text = truncateText(e.Footer.Text, maxFooterLength)
+	if len(e.Fields) > maxFields {
+		e.Fields = e.Fields[:maxFields]
+	}
+	for i := range e.Fields {
+		e.Fields[i].Name = truncateText(e.Fields[i].Name, maxFieldNameLength)
+		e.Fields[i].Value = truncateText(e.Fields[i].Value, maxFieldValueLength)
+	}
+
+	// Fields that would push the embed past its total length are dropped,
+	// keeping any smaller fields further down that still fit.
+	total := embedLength(e)
+	fields := e.Fields[:0]
+	for _, f := range e.Fields {
+		n := utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+		if total+n > maxEmbedLength {
+			continue
+		}
+		total += n
+		fields = append(fields, f)
+	}
+	e.Fields = fields
+	return e
+}
+
+// embedLength returns the number of runes Discord counts towards the total
+// embed length, excluding the fields.
+func embedLength(e EmbedObject) int {
+	return utf8.RuneCountInString(e.Title) +
+		utf8.RuneCountInString(e.Description) +
+		utf8.RuneCountInString(e.Author.Name) +
+		utf8.RuneCountInString(e.Footer.Text)
+}
+
+// splitContent splits the text into chunks of at most n runes, preferring
+// to break after a newline.
+func splitContent(s string, n int) []string {
+	var chunks []string
+	for utf8.RuneCountInString(s) > n {
+		runes := []rune(s)
+		cut := n
+		for i := n - 1; i > 0; i-- {
+			if runes[i] == '\n' {
+				cut = i + 1
+				break
+			}
+		}
+		chunks = append(chunks, string(runes[:cut]))
+		s = string(runes[cut:])
+	}
+	if s != "" {
+		chunks = append(chunks, s)
+	}
+	return chunks
+}
+
+// splitCodeBlock splits the text into code blocks of at most
+// maxContentLength runes each.
+func splitCodeBlock(lang, s string) []string {
+	s = strings.TrimRight(strings.ReplaceAll(s, "```", "`\u200b``"), "\n")
+	open, end := "```"+lang+"\n", "\n```"
+	chunks := splitContent(s, maxContentLength-utf8.RuneCountInString(open+end))
+	for i, c := range chunks {
+		chunks[i] = open + strings.TrimRight(c, "\n") + end
+	}
+	return chunks
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultLocale is used for missing locales and translations.
+const DefaultLocale = "en"
+
+// catalog maps message keys to translated format strings.
+type catalog map[string]string
+
+// catalogs holds the translations of the default template.
+var catalogs = map[string]catalog{
+	"en": {
+		"github_triggered":          "%s/%s triggered by %s (%s)",
+		"pushed_to":                 "%s pushed to %s",
+		"updated_pull_request":      "%s updated pull request %s",
+		"pushed_tag":                "%s pushed tag %s",
+		"updated_pull_request_link": "%s updated pull request %s (%s)",
+		"promoted":                  "%s promoted build #%d to %s",
+		"rolled_back":               "%s rolled back %s to build #%d",
+		"deployed":                  "%s deployed %s to %s",
+		"cron":                      "cron job %s triggered a build on %s",
+		"triggered":                 "%s triggered a build on %s",
+		"published_release":         "%s published release %s",
+		"more_commits":              "and %d more commits",
+		"breaking_changes":          "Breaking Changes",
+		"features":                  "Features",
+		"fixes":                     "Fixes",
+		"other_changes":             "Other Changes",
+		"changes":                   "Changes",
+		"diffstat":                  "%d files changed, %d insertions(+), %d deletions(-)",
+		"security":                  "Security",
+		"security_none":             "No findings",
+		"security_summary":          "%d critical, %d high, %d medium, %d low",
+		"top_findings":              "Top findings",
+		"more_findings":             "and %d more",
+		"coverage":                  "Coverage",
+		"coverage_below":            "below the threshold of %.1f%%",
+		"packages":                  "Packages",
+		"package_summary":           "%d passed, %d failed, %d skipped",
+		"tests":                     "Tests",
+		"test_summary":              "%d passed, %d failed, %d skipped in %s",
+		"failed_tests":              "Failed tests",
+		"more_failures":             "and %d more",
+		"took":                      "%s (took %s)",
+		"queued":                    "Queued",
+		"started":                   "Started",
+		"finished":                  "Finished",
+		"powered_by":                DroneDesc,
+		"time_layout":               TimeLayout,
+		TransitionFixed:             "Fixed",
+		TransitionBroken:            "Broken",
+		TransitionStillFailing:      "Still failing",
+	},
+	"ja": {
+		"github_triggered":          "%s/%s が %s によって実行されました (%s)",
+		"pushed_to":                 "%s が %s にプッシュしました",
+		"updated_pull_request":      "%s がプルリクエスト %s を更新しました",
+		"pushed_tag":                "%s がタグ %s をプッシュしました",
+		"updated_pull_request_link": "%s がプルリクエスト %s (%s) を更新しました",
+		"promoted":                  "%s がビルド #%d を %s にプロモートしました",
+		"rolled_back":               "%s が %s をビルド #%d にロールバックしました",
+		"deployed":                  "%s が %s を %s にデプロイしました",
+		"cron":                      "cron ジョブ %s が %s のビルドを実行しました",
+		"triggered":                 "%s が %s のビルドを実行しました",
+		"published_release":         "%s がリリース %s を公開しました",
+		"more_commits":              "他 %d 件のコミット",
+		"breaking_changes":          "破壊的変更",
+		"features":                  "新機能",
+		"fixes":                     "バグ修正",
+		"other_changes":             "その他の変更",
+		"changes":                   "変更",
+		"diffstat":                  "%d ファイル変更、%d 行追加(+)、%d 行削除(-)",
+		"security":                  "セキュリティ",
+		"security_none":             "検出なし",
+		"security_summary":          "緊急 %d 件、高 %d 件、中 %d 件、低 %d 件",
+		"top_findings":              "主な検出結果",
+		"more_findings":             "他 %d 件",
+		"coverage":                  "カバレッジ",
+		"coverage_below":            "しきい値 %.1f%% を下回っています",
+		"packages":                  "パッケージ",
+		"package_summary":           "成功 %d 件、失敗 %d 件、スキップ %d 件",
+		"tests":                     "テスト",
+		"test_summary":              "成功 %d 件、失敗 %d 件、スキップ %d 件 (%s)",
+		"failed_tests":              "失敗したテスト",
+		"more_failures":             "他 %d 件",
+		"took":                      "%s (所要時間 %s)",
+		"queued":                    "キュー登録",
+		"started":                   "開始",
+		"finished":                  "終了",
+		"powered_by":                "Drone Discord Plugin による通知",
+		"time_layout":               "2006年1月2日 15:04:05 MST",
+		TransitionFixed:             "修正",
+		TransitionBroken:            "失敗",
+		TransitionStillFailing:      "失敗継続",
+	},
+	"de": {
+		"github_triggered":          "%s/%s ausgelöst von %s (%s)",
+		"pushed_to":                 "%s hat nach %s gepusht",
+		"updated_pull_request":      "%s hat den Pull Request %s aktualisiert",
+		"pushed_tag":                "%s hat den Tag %s gepusht",
+		"updated_pull_request_link": "%s hat den Pull Request %s (%s) aktualisiert",
+		"promoted":                  "%s hat Build #%d nach %s promotet",
+		"rolled_back":               "%s hat %s auf Build #%d zurückgesetzt",
+		"deployed":                  "%s hat %s nach %s deployt",
+		"cron":                      "Cron-Job %s hat einen Build auf %s gestartet",
+		"triggered":                 "%s hat einen Build auf %s gestartet",
+		"published_release":         "%s hat das Release %s veröffentlicht",
+		"more_commits":              "und %d weitere Commits",
+		"breaking_changes":          "Inkompatible Änderungen",
+		"features":                  "Neue Funktionen",
+		"fixes":                     "Fehlerbehebungen",
+		"other_changes":             "Weitere Änderungen",
+		"changes":                   "Änderungen",
+		"diffstat":                  "%d Dateien geändert, %d Einfügungen(+), %d Löschungen(-)",
+		"security":                  "Sicherheit",
+		"security_none":             "Keine Befunde",
+		"security_summary":          "%d kritisch, %d hoch, %d mittel, %d niedrig",
+		"top_findings":              "Wichtigste Befunde",
+		"more_findings":             "und %d weitere",
+		"coverage":                  "Abdeckung",
+		"coverage_below":            "unter dem Schwellenwert von %.1f%%",
+		"packages":                  "Pakete",
+		"package_summary":           "%d bestanden, %d fehlgeschlagen, %d übersprungen",
+		"tests":                     "Tests",
+		"test_summary":              "%d bestanden, %d fehlgeschlagen, %d übersprungen in %s",
+		"failed_tests":              "Fehlgeschlagene Tests",
+		"more_failures":             "und %d weitere",
+		"took":                      "%s (Dauer %s)",
+		"queued":                    "Eingereiht",
+		"started":                   "Gestartet",
+		"finished":                  "Beendet",
+		"powered_by":                "Bereitgestellt vom Drone Discord Plugin",
+		"time_layout":               "02.01.2006 15:04:05 MST",
+		TransitionFixed:             "Behoben",
+		TransitionBroken:            "Fehlgeschlagen",
+		TransitionStillFailing:      "Weiterhin fehlgeschlagen",
+	},
+}
+
+// normalizeLocale reduces a locale such as "de_DE.UTF-8" or "ja-JP" to
+// its language code.
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, "_-."); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
+
+// tr translates the message key into the configured locale, falling back
+// to English.
+func (p *Plugin) tr(key string, args ...interface{}) string {
+	format, ok := catalogs[normalizeLocale(p.Config.Locale)][key]
+	if !ok {
+		format = catalogs[DefaultLocale][key]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
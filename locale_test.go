@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	assert.Equal(t, "de", normalizeLocale("de_DE.UTF-8"))
+	assert.Equal(t, "ja", normalizeLocale("ja-JP"))
+	assert.Equal(t, "en", normalizeLocale("EN"))
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	for locale, c := range catalogs {
+		for key := range catalogs[DefaultLocale] {
+			assert.NotEmpty(t, c[key], "%s is missing %s", locale, key)
+		}
+	}
+}
+
+func TestLocalizedTemplate(t *testing.T) {
+	started := time.Date(2024, time.May, 1, 10, 0, 0, 0, time.Local)
+
+	p := Plugin{
+		Commit: Commit{Author: "appleboy", Branch: "main", Message: "fix: typo"},
+		Build: Build{
+			Event:      "push",
+			Status:     "success",
+			PrevStatus: "failure",
+			Transition: TransitionFixed,
+			Started:    started.Unix(),
+			Finished:   started.Add(time.Minute).Unix(),
+		},
+		Config: Config{Locale: "de_DE", NotifyOn: NotifyChanges},
+	}
+
+	object := p.Template()
+	assert.Equal(t, "[Behoben] fix: typo", object.Title)
+	assert.Equal(t, "appleboy hat nach main gepusht (Dauer 1m0s)", object.Description)
+	assert.Equal(t, "Gestartet", object.Fields[0].Name)
+	assert.Equal(t, started.Format("02.01.2006 15:04:05 MST"), object.Fields[0].Value)
+	assert.Equal(t, "Bereitgestellt vom Drone Discord Plugin", object.Footer.Text)
+
+	p.Config.Locale = "ja"
+	assert.Equal(t, "appleboy が main にプッシュしました (所要時間 1m0s)", p.Template().Description)
+
+	p.Config.Locale = "fr"
+	assert.Equal(t, "appleboy pushed to main (took 1m0s)", p.Template().Description)
+	assert.Equal(t, DroneDesc, p.Template().Footer.Text)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// stdin is the input read for "-" paths.
+var stdin io.Reader = os.Stdin
+
+var (
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+)
+
+// stripANSI removes terminal color and control sequences.
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// tail returns the last n lines of the reader.
+func tail(r io.Reader, n int) ([]string, error) {
+	lines := make([]string, 0, n)
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			if len(lines) == n {
+				lines = append(lines[:0], lines[1:]...)
+			}
+			lines = append(lines, strings.TrimRight(line, "\r\n"))
+		}
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// loadLog reads the tail of the log of a failed build, posted as a code
+// block or attached when it exceeds the content limit.
+func (p *Plugin) loadLog() error {
+	if p.Config.LogFile == "" || p.Config.LogLines <= 0 || !isFailure(p.Build.Status) {
+		return nil
+	}
+
+	name := filepath.Base(p.Config.LogFile)
+	r := stdin
+	if p.Config.LogFile == "-" {
+		name = "build.log"
+	} else {
+		// the build already failed, so a missing log must not stop the
+		// notification
+		f, err := os.Open(filepath.Clean(p.Config.LogFile))
+		if err != nil {
+			log.Printf("skipping log file: %v", err)
+			return nil
+		}
+		defer f.Close()
+		r = f
+	}
+
+	lines, err := tail(r, p.Config.LogLines)
+	if err != nil {
+		log.Printf("skipping log file: %v", err)
+		return nil
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	// redact first so the limit applies to the content actually sent
+	content := p.redactor.String(stripANSI(strings.Join(lines, "\n")))
+	if block := string(codeBlock("", content)); utf8.RuneCountInString(block) <= maxContentLength {
+		p.logTail = block
+		return nil
+	}
+	if !strings.Contains(name, ".") {
+		name += ".log"
+	}
+	p.attachments = append(p.attachments, attachment{Name: name, Content: []byte(content + "\n")})
+	return nil
+}
+
+// handleLog sends the log tail as a plain message.
+func (p *Plugin) handleLog(ctx context.Context) error {
+	if p.logTail == "" {
+		return nil
+	}
+
+	p.Clear()
+	p.Payload.Content = p.logTail
+	if err := p.SendMessage(ctx); err != nil {
+		return fmt.Errorf("failed to send log: %w", err)
+	}
+	p.Clear()
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTail(t *testing.T) {
+	lines, err := tail(strings.NewReader("a\nb\r\nc\nd"), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "d"}, lines)
+}
+
+func TestStripANSI(t *testing.T) {
+	assert.Equal(t, "FAIL ok link", stripANSI("\x1b[31;1mFAIL\x1b[0m ok \x1b]8;;http://x\x07link\x1b]8;;\x07"))
+}
+
+func TestExecWithLogTail(t *testing.T) {
+	t.Setenv("NPM_TOKEN", "npm-secret-token")
+	srv, rec := newWebhookServer(t)
+
+	stdin = strings.NewReader("line 1\nline 2\n\x1b[31mnpm ERR! auth npm-secret-token\x1b[0m\n")
+	defer func() { stdin = os.Stdin }()
+
+	plugin := Plugin{
+		Build: Build{Status: "failure"},
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/token",
+			AllowedHosts: testHosts,
+			LogFile:      "-",
+			LogLines:     2,
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 2)
+	assert.Contains(t, bodies[1], `"content":"`+"```"+`\nline 2\nnpm ERR! auth ***\n`+"```"+`"`)
+}
+
+func TestExecWithMissingLogFile(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	plugin := Plugin{
+		Build: Build{Status: "failure"},
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/token",
+			AllowedHosts: testHosts,
+			LogFile:      filepath.Join(t.TempDir(), "missing.log"),
+			LogLines:     10,
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 1)
+	assert.Contains(t, bodies[0], `"embeds":[{`)
+}
+
+func TestExecWithRedactedLogTail(t *testing.T) {
+	secret := strings.Repeat("s", 100)
+	t.Setenv("NPM_TOKEN", secret)
+	srv, rec := newWebhookServer(t)
+
+	// the raw log exceeds the content limit, the redacted one does not
+	stdin = strings.NewReader(strings.Repeat("auth "+secret+"\n", 30))
+	defer func() { stdin = os.Stdin }()
+
+	plugin := Plugin{
+		Build: Build{Status: "failure"},
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/token",
+			AllowedHosts: testHosts,
+			LogFile:      "-",
+			LogLines:     30,
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 2)
+	assert.Contains(t, bodies[1], `"content":"`+"```"+`\nauth ***\n`)
+	assert.NotContains(t, bodies[1], secret)
+}
+
+func TestExecWithLongLogTail(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+	file := filepath.Join(t.TempDir(), "build.log")
+	var b strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&b, "step %d: compiling package\n", i)
+	}
+	assert.NoError(t, os.WriteFile(file, []byte(b.String()), 0o600))
+
+	plugin := Plugin{
+		Build: Build{Status: "failure"},
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/token",
+			AllowedHosts: testHosts,
+			LogFile:      file,
+			LogLines:     100,
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 2)
+	assert.Contains(t, bodies[1], `filename="build.log"`)
+	assert.Contains(t, bodies[1], "step 100: compiling package")
+	assert.NotContains(t, bodies[1], "step 99:")
+
+	plugin = Plugin{
+		Build:  Build{Status: "success"},
+		Config: plugin.Config,
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+	_, bodies = rec.requests()
+	assert.Len(t, bodies, 3)
+}
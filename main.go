@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -35,8 +36,40 @@ func main() {
 		},
 	}
 	app.Action = run
+	app.Commands = []*cli.Command{
+		{
+			Name:   "validate",
+			Usage:  "Check the webhooks and show their name, channel, guild and type without posting",
+			Action: validate,
+		},
+		{
+			Name:   "render",
+			Usage:  "Render the message templates against a JSON or YAML context without sending them",
+			Action: render,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "context",
+					Usage:    "The JSON or YAML file with the repo, commit, build and other values used by templates.",
+					Required: true,
+				},
+				&cli.StringSliceFlag{
+					Name:  "template",
+					Usage: "Files containing the message templates, instead of the configured messages.",
+				},
+			},
+		},
+	}
 	app.Version = Version
-	app.Flags = []cli.Flag{
+	app.Flags = flags()
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// flags returns the command line flags of the plugin.
+func flags() []cli.Flag {
+	flags := []cli.Flag{
 		&cli.StringFlag{
 			Name:    "webhook-url",
 			Usage:   "The Discord webhook URL to send messages to.",
@@ -52,11 +85,55 @@ func main() {
 			Usage:   "The Discord webhook token.",
 			EnvVars: []string{"PLUGIN_WEBHOOK_TOKEN", "WEBHOOK_TOKEN", "DISCORD_WEBHOOK_TOKEN", "INPUT_WEBHOOK_TOKEN"},
 		},
+		&cli.StringFlag{
+			Name:    "webhooks",
+			Usage:   "Additional webhooks as YAML or JSON, each a URL or an object with url or id and token and optional username, avatar_url and thread_id.",
+			EnvVars: []string{"PLUGIN_WEBHOOKS", "DISCORD_WEBHOOKS", "INPUT_WEBHOOKS"},
+		},
+		&cli.StringFlag{
+			Name:    "thread-id",
+			Usage:   "Send messages to the given thread in the webhook's channel.",
+			EnvVars: []string{"PLUGIN_THREAD_ID", "DISCORD_THREAD_ID", "THREAD_ID", "INPUT_THREAD_ID"},
+		},
+		&cli.IntFlag{
+			Name:    "max-concurrency",
+			Value:   4,
+			Usage:   "The maximum number of webhooks notified concurrently.",
+			EnvVars: []string{"PLUGIN_MAX_CONCURRENCY", "MAX_CONCURRENCY", "INPUT_MAX_CONCURRENCY"},
+		},
+		&cli.StringFlag{
+			Name:    "fail-policy",
+			Value:   FailAny,
+			Usage:   "Fail when any webhook could not be notified (any) or only when all failed (all).",
+			EnvVars: []string{"PLUGIN_FAIL_POLICY", "FAIL_POLICY", "INPUT_FAIL_POLICY"},
+		},
 		&cli.StringSliceFlag{
 			Name:    "message",
 			Usage:   "The message contents to send to the Discord channel (up to 2000 characters).",
 			EnvVars: []string{"PLUGIN_MESSAGE", "DISCORD_MESSAGE", "MESSAGE", "INPUT_MESSAGE"},
 		},
+		&cli.StringSliceFlag{
+			Name:    "template-file",
+			Usage:   "The files containing message templates to send to the Discord channel.",
+			EnvVars: []string{"PLUGIN_TEMPLATE_FILE", "DISCORD_TEMPLATE_FILE", "TEMPLATE_FILE", "INPUT_TEMPLATE_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "template-dir",
+			Usage:   "The directory of partial templates, included by name with {{> name}}.",
+			EnvVars: []string{"PLUGIN_TEMPLATE_DIR", "DISCORD_TEMPLATE_DIR", "TEMPLATE_DIR", "INPUT_TEMPLATE_DIR"},
+		},
+		&cli.StringFlag{
+			Name:    "template-engine",
+			Value:   EngineHandlebars,
+			Usage:   "The template engine used to render messages: handlebars or go.",
+			EnvVars: []string{"PLUGIN_TEMPLATE_ENGINE", "DISCORD_TEMPLATE_ENGINE", "TEMPLATE_ENGINE", "INPUT_TEMPLATE_ENGINE"},
+		},
+		&cli.StringFlag{
+			Name:    "locale",
+			Value:   DefaultLocale,
+			Usage:   "The language of the default message: en, ja or de.",
+			EnvVars: []string{"PLUGIN_LOCALE", "DISCORD_LOCALE", "INPUT_LOCALE"},
+		},
 		&cli.StringSliceFlag{
 			Name:    "file",
 			Usage:   "The contents of the file being sent to the Discord channel.",
@@ -67,6 +144,11 @@ func main() {
 			Usage:   "The color code of the embed message.",
 			EnvVars: []string{"PLUGIN_COLOR", "COLOR", "INPUT_COLOR"},
 		},
+		&cli.StringFlag{
+			Name:    "mode",
+			Usage:   "Send custom messages as embed or plain text. Defaults to embed when a color is set.",
+			EnvVars: []string{"PLUGIN_MODE", "MODE", "INPUT_MODE"},
+		},
 		&cli.BoolFlag{
 			Name:    "wait",
 			Usage:   "Wait for server confirmation of message send before response, and return the created message body.",
@@ -87,6 +169,179 @@ func main() {
 			Usage:   "Override the default avatar of the webhook.",
 			EnvVars: []string{"PLUGIN_AVATAR_URL", "AVATAR_URL", "INPUT_AVATAR_URL"},
 		},
+		&cli.StringFlag{
+			Name:    "notify-on",
+			Value:   NotifyAlways,
+			Usage:   "When to send notifications: always or changes (only when the build status changed or keeps failing).",
+			EnvVars: []string{"PLUGIN_NOTIFY_ON", "NOTIFY_ON", "INPUT_NOTIFY_ON"},
+		},
+		&cli.StringFlag{
+			Name:    "state-file",
+			Usage:   "The file used to store the build status when the previous build status is not provided by the CI.",
+			EnvVars: []string{"PLUGIN_STATE_FILE", "STATE_FILE", "INPUT_STATE_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "rules",
+			Usage:   "Routing rules as YAML or JSON, or the path to a rules file.",
+			EnvVars: []string{"PLUGIN_RULES", "DISCORD_RULES", "INPUT_RULES"},
+		},
+		&cli.BoolFlag{
+			Name:    "dry-run",
+			Usage:   "Print the requests that would be sent and explain which routing rules match, without sending them.",
+			EnvVars: []string{"PLUGIN_DRY_RUN", "DRY_RUN", "INPUT_DRY_RUN"},
+		},
+		&cli.StringFlag{
+			Name:    "workspace",
+			Usage:   "The path to the git checkout of the repository.",
+			EnvVars: []string{"PLUGIN_WORKSPACE", "DRONE_WORKSPACE", "CI_WORKSPACE", "GITHUB_WORKSPACE"},
+		},
+		&cli.IntFlag{
+			Name:    "max-commits",
+			Value:   10,
+			Usage:   "The maximum number of commits of a push listed in the default message, 0 to disable.",
+			EnvVars: []string{"PLUGIN_MAX_COMMITS", "MAX_COMMITS", "INPUT_MAX_COMMITS"},
+		},
+		&cli.BoolFlag{
+			Name:    "diffstat",
+			Usage:   "Add the number of changed files and lines and the top directories touched to the default message.",
+			EnvVars: []string{"PLUGIN_DIFFSTAT", "DIFFSTAT", "INPUT_DIFFSTAT"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "ignore-paths",
+			Usage:   "Skip the notification when only files matching these globs changed.",
+			EnvVars: []string{"PLUGIN_IGNORE_PATHS", "IGNORE_PATHS", "INPUT_IGNORE_PATHS"},
+		},
+		&cli.BoolFlag{
+			Name:    "changelog",
+			Usage:   "Add a changelog since the previous tag, grouped by conventional commit type, to the default message of tag builds.",
+			EnvVars: []string{"PLUGIN_CHANGELOG", "CHANGELOG", "INPUT_CHANGELOG"},
+		},
+		&cli.BoolFlag{
+			Name:    "changelog-attach",
+			Usage:   "Attach the full changelog as CHANGELOG.md when it exceeds the embed limits.",
+			EnvVars: []string{"PLUGIN_CHANGELOG_ATTACH", "CHANGELOG_ATTACH", "INPUT_CHANGELOG_ATTACH"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "junit",
+			Usage:   "Glob patterns of JUnit XML reports summarized in the message.",
+			EnvVars: []string{"PLUGIN_JUNIT", "JUNIT", "INPUT_JUNIT"},
+		},
+		&cli.IntFlag{
+			Name:    "junit-max-failures",
+			Value:   5,
+			Usage:   "The maximum number of failed tests listed in the message.",
+			EnvVars: []string{"PLUGIN_JUNIT_MAX_FAILURES", "JUNIT_MAX_FAILURES", "INPUT_JUNIT_MAX_FAILURES"},
+		},
+		&cli.BoolFlag{
+			Name:    "junit-attach",
+			Usage:   "Attach the JUnit XML reports to the message.",
+			EnvVars: []string{"PLUGIN_JUNIT_ATTACH", "JUNIT_ATTACH", "INPUT_JUNIT_ATTACH"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "go-test",
+			Usage:   "Glob patterns of go test -json output summarized in the message.",
+			EnvVars: []string{"PLUGIN_GO_TEST", "GO_TEST", "INPUT_GO_TEST"},
+		},
+		&cli.IntFlag{
+			Name:    "go-test-max-failures",
+			Value:   5,
+			Usage:   "The maximum number of failed tests listed in the message.",
+			EnvVars: []string{"PLUGIN_GO_TEST_MAX_FAILURES", "GO_TEST_MAX_FAILURES", "INPUT_GO_TEST_MAX_FAILURES"},
+		},
+		&cli.IntFlag{
+			Name:    "go-test-lines",
+			Value:   10,
+			Usage:   "The number of output lines shown for each failed test.",
+			EnvVars: []string{"PLUGIN_GO_TEST_LINES", "GO_TEST_LINES", "INPUT_GO_TEST_LINES"},
+		},
+		&cli.BoolFlag{
+			Name:    "go-test-attach",
+			Usage:   "Attach the go test -json output to the message.",
+			EnvVars: []string{"PLUGIN_GO_TEST_ATTACH", "GO_TEST_ATTACH", "INPUT_GO_TEST_ATTACH"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "coverage",
+			Usage:   "Glob patterns of Cobertura XML, LCOV or Go coverprofile reports summarized in the message.",
+			EnvVars: []string{"PLUGIN_COVERAGE", "COVERAGE", "INPUT_COVERAGE"},
+		},
+		&cli.StringFlag{
+			Name:    "coverage-baseline",
+			Usage:   "The previous coverage in percent, or a file containing it or a coverage report.",
+			EnvVars: []string{"PLUGIN_COVERAGE_BASELINE", "COVERAGE_BASELINE", "INPUT_COVERAGE_BASELINE"},
+		},
+		&cli.Float64Flag{
+			Name:    "coverage-threshold",
+			Usage:   "The minimum coverage in percent, lower coverage colors the message red.",
+			EnvVars: []string{"PLUGIN_COVERAGE_THRESHOLD", "COVERAGE_THRESHOLD", "INPUT_COVERAGE_THRESHOLD"},
+		},
+		&cli.BoolFlag{
+			Name:    "coverage-fail",
+			Usage:   "Fail the step when the coverage is below the threshold.",
+			EnvVars: []string{"PLUGIN_COVERAGE_FAIL", "COVERAGE_FAIL", "INPUT_COVERAGE_FAIL"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "security",
+			Usage:   "Glob patterns of SARIF or Trivy JSON reports summarized in the message.",
+			EnvVars: []string{"PLUGIN_SECURITY", "SECURITY", "INPUT_SECURITY"},
+		},
+		&cli.IntFlag{
+			Name:    "security-max-findings",
+			Value:   5,
+			Usage:   "The maximum number of findings listed in the message.",
+			EnvVars: []string{"PLUGIN_SECURITY_MAX_FINDINGS", "SECURITY_MAX_FINDINGS", "INPUT_SECURITY_MAX_FINDINGS"},
+		},
+		&cli.BoolFlag{
+			Name:    "security-attach",
+			Usage:   "Attach the security reports to the message.",
+			EnvVars: []string{"PLUGIN_SECURITY_ATTACH", "SECURITY_ATTACH", "INPUT_SECURITY_ATTACH"},
+		},
+		&cli.StringFlag{
+			Name:    "log-file",
+			Usage:   "The log file whose last lines are posted when the build failed, - reads stdin.",
+			EnvVars: []string{"PLUGIN_LOG_FILE", "LOG_FILE", "INPUT_LOG_FILE"},
+		},
+		&cli.IntFlag{
+			Name:    "log-lines",
+			Value:   50,
+			Usage:   "The number of log lines posted when the build failed.",
+			EnvVars: []string{"PLUGIN_LOG_LINES", "LOG_LINES", "INPUT_LOG_LINES"},
+		},
+		&cli.BoolFlag{
+			Name:    "message-from-stdin",
+			Usage:   "Send the standard input as plain message, split at the content limit.",
+			EnvVars: []string{"PLUGIN_MESSAGE_FROM_STDIN", "MESSAGE_FROM_STDIN", "INPUT_MESSAGE_FROM_STDIN"},
+		},
+		&cli.BoolFlag{
+			Name:    "code-block",
+			Usage:   "Wrap the message read from standard input in a code block.",
+			EnvVars: []string{"PLUGIN_CODE_BLOCK", "CODE_BLOCK", "INPUT_CODE_BLOCK"},
+		},
+		&cli.StringFlag{
+			Name:    "code-language",
+			Usage:   "The language hint of the code block wrapping the message read from standard input.",
+			EnvVars: []string{"PLUGIN_CODE_LANGUAGE", "CODE_LANGUAGE", "INPUT_CODE_LANGUAGE"},
+		},
+		&cli.StringFlag{
+			Name:    "stdin-filename",
+			Value:   "stdin.txt",
+			Usage:   "The file name of the standard input uploaded with file -.",
+			EnvVars: []string{"PLUGIN_STDIN_FILENAME", "STDIN_FILENAME", "INPUT_STDIN_FILENAME"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "redact-env",
+			Usage:   "Globs of environment variable names whose values are masked in addition to *_TOKEN, *_SECRET, *_PASSWORD and *_KEY.",
+			EnvVars: []string{"PLUGIN_REDACT_ENV", "REDACT_ENV", "INPUT_REDACT_ENV"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "redact-regex",
+			Usage:   "Regular expressions whose matches are masked in everything sent.",
+			EnvVars: []string{"PLUGIN_REDACT_REGEX", "REDACT_REGEX", "INPUT_REDACT_REGEX"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "allowed-hosts",
+			Usage:   "Hosts accepted in webhook URLs besides the Discord domains, such as proxies, which may also use http.",
+			EnvVars: []string{"PLUGIN_ALLOWED_HOSTS", "ALLOWED_HOSTS", "INPUT_ALLOWED_HOSTS"},
+		},
 		&cli.BoolFlag{
 			Name:    "drone",
 			Usage:   "Indicate if the environment is Drone CI.",
@@ -128,6 +383,16 @@ func main() {
 			Usage:   "The Git commit branch.",
 			EnvVars: []string{"DRONE_COMMIT_BRANCH", "CI_COMMIT_BRANCH"},
 		},
+		&cli.StringFlag{
+			Name:    "commit.before",
+			Usage:   "The previous commit SHA of the push.",
+			EnvVars: []string{"DRONE_COMMIT_BEFORE", "CI_PREV_COMMIT_SHA"},
+		},
+		&cli.StringFlag{
+			Name:    "commit.after",
+			Usage:   "The latest commit SHA of the push.",
+			EnvVars: []string{"DRONE_COMMIT_AFTER"},
+		},
 		&cli.StringFlag{
 			Name:    "commit.link",
 			Usage:   "The link to the Git commit.",
@@ -176,6 +441,11 @@ func main() {
 			Value:   "success",
 			EnvVars: []string{"DRONE_BUILD_STATUS", "CI_PIPELINE_STATUS"},
 		},
+		&cli.StringFlag{
+			Name:    "build.prev.status",
+			Usage:   "The status of the previous build.",
+			EnvVars: []string{"DRONE_PREV_BUILD_STATUS", "CI_PREV_PIPELINE_STATUS"},
+		},
 		&cli.StringFlag{
 			Name:    "build.link",
 			Usage:   "The link to the build.",
@@ -191,6 +461,11 @@ func main() {
 			Usage:   "The pull request number.",
 			EnvVars: []string{"DRONE_PULL_REQUEST", "CI_COMMIT_PULL_REQUEST"},
 		},
+		&cli.Int64Flag{
+			Name:    "build.created",
+			Usage:   "The timestamp when the build was created.",
+			EnvVars: []string{"DRONE_BUILD_CREATED", "CI_PIPELINE_CREATED"},
+		},
 		&cli.Int64Flag{
 			Name:    "build.started",
 			Usage:   "The timestamp when the build started.",
@@ -236,6 +511,11 @@ func main() {
 			Usage:   "The target deployment environment for the running build. This value is only available to promotion and rollback pipelines.",
 			EnvVars: []string{"DRONE_DEPLOY_TO", "CI_PIPELINE_DEPLOY_TARGET"},
 		},
+		&cli.StringFlag{
+			Name:    "build.cron",
+			Usage:   "The name of the cron job that triggered the build.",
+			EnvVars: []string{"DRONE_CRON", "CI_PIPELINE_CRON"},
+		},
 		&cli.BoolFlag{
 			Name:    "debug",
 			Usage:   "Enable debug mode.",
@@ -243,12 +523,71 @@ func main() {
 		},
 	}
 
-	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
+	for _, status := range []string{StatusSuccess, StatusFailure, StatusStarted} {
+		flags = append(flags, statusFlags(status)...)
 	}
+	return flags
 }
 
-func run(c *cli.Context) error {
+// statusFlags returns the message flags of a build status category.
+func statusFlags(status string) []cli.Flag {
+	env := func(flag string) []string {
+		name := strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+		return []string{"PLUGIN_" + name, "DISCORD_" + name, "INPUT_" + name}
+	}
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "message-" + status,
+			Usage:   "The message contents to send when the build status is " + status + ".",
+			EnvVars: env("message-" + status),
+		},
+		&cli.StringFlag{
+			Name:    "message-" + status + "-file",
+			Usage:   "The file containing the message template to send when the build status is " + status + ".",
+			EnvVars: env("message-" + status + "-file"),
+		},
+		&cli.StringFlag{
+			Name:    "color-" + status,
+			Usage:   "The color code of the embed message when the build status is " + status + ".",
+			EnvVars: env("color-" + status),
+		},
+		&cli.StringFlag{
+			Name:    "mode-" + status,
+			Usage:   "Send messages as embed or plain text when the build status is " + status + ".",
+			EnvVars: env("mode-" + status),
+		},
+	}
+}
+
+// statusMessages reads the message variants of every build status category.
+func statusMessages(c *cli.Context) map[string]StatusMessage {
+	messages := map[string]StatusMessage{}
+	for _, status := range []string{StatusSuccess, StatusFailure, StatusStarted} {
+		m := StatusMessage{
+			Message: c.StringSlice("message-" + status),
+			File:    c.String("message-" + status + "-file"),
+			Color:   c.String("color-" + status),
+			Mode:    c.String("mode-" + status),
+		}
+		if len(m.Message) > 0 || m.File != "" || m.Color != "" || m.Mode != "" {
+			messages[status] = m
+		}
+	}
+	return messages
+}
+
+// newPlugin builds the plugin from the flags.
+func newPlugin(c *cli.Context) (Plugin, error) {
+	rules, err := parseRules(c.String("rules"))
+	if err != nil {
+		return Plugin{}, err
+	}
+
+	webhooks, err := parseWebhooks(c.String("webhooks"))
+	if err != nil {
+		return Plugin{}, err
+	}
+
 	plugin := Plugin{
 		GitHub: GitHub{
 			Workflow:  c.String("github.workflow"),
@@ -271,6 +610,8 @@ func run(c *cli.Context) error {
 			Email:   c.String("commit.author.email"),
 			Avatar:  c.String("commit.author.avatar"),
 			Message: c.String("commit.message"),
+			Before:  c.String("commit.before"),
+			After:   c.String("commit.after"),
 		},
 		Source: Source{
 			Branch: c.String("source.branch"),
@@ -281,21 +622,68 @@ func run(c *cli.Context) error {
 			Event:    c.String("build.event"),
 			Status:   c.String("build.status"),
 			Link:     c.String("build.link"),
+			Created:  c.Int64("build.created"),
 			Started:  c.Int64("build.started"),
 			Finished: c.Int64("build.finished"),
 			PR:       c.String("pull.request"),
 			DeployTo: c.String("deploy.to"),
+			Cron:     c.String("build.cron"),
+
+			PrevStatus: c.String("build.prev.status"),
 		},
 		Config: Config{
-			webhookURL:   c.String("webhook-url"),
-			WebhookID:    c.String("webhook-id"),
-			WebhookToken: c.String("webhook-token"),
-			Message:      c.StringSlice("message"),
-			File:         c.StringSlice("file"),
-			Color:        c.String("color"),
-			Drone:        c.Bool("drone") || c.String("ci.environment") == "woodpecker",
-			GitHub:       c.Bool("github"),
-			Debug:        c.Bool("debug"),
+			webhookURL:          c.String("webhook-url"),
+			WebhookID:           c.String("webhook-id"),
+			WebhookToken:        c.String("webhook-token"),
+			ThreadID:            c.String("thread-id"),
+			Webhooks:            webhooks,
+			MaxConcurrency:      c.Int("max-concurrency"),
+			FailPolicy:          c.String("fail-policy"),
+			Message:             c.StringSlice("message"),
+			TemplateFile:        c.StringSlice("template-file"),
+			TemplateDir:         c.String("template-dir"),
+			TemplateEngine:      c.String("template-engine"),
+			Locale:              c.String("locale"),
+			File:                c.StringSlice("file"),
+			Color:               c.String("color"),
+			Mode:                c.String("mode"),
+			StatusMessages:      statusMessages(c),
+			NotifyOn:            c.String("notify-on"),
+			StateFile:           c.String("state-file"),
+			Workspace:           c.String("workspace"),
+			MaxCommits:          c.Int("max-commits"),
+			Diffstat:            c.Bool("diffstat"),
+			IgnorePaths:         c.StringSlice("ignore-paths"),
+			Changelog:           c.Bool("changelog"),
+			ChangelogAttach:     c.Bool("changelog-attach"),
+			JUnit:               c.StringSlice("junit"),
+			JUnitMaxFailures:    c.Int("junit-max-failures"),
+			JUnitAttach:         c.Bool("junit-attach"),
+			GoTest:              c.StringSlice("go-test"),
+			GoTestMaxFailures:   c.Int("go-test-max-failures"),
+			GoTestLines:         c.Int("go-test-lines"),
+			GoTestAttach:        c.Bool("go-test-attach"),
+			Coverage:            c.StringSlice("coverage"),
+			CoverageBaseline:    c.String("coverage-baseline"),
+			CoverageThreshold:   c.Float64("coverage-threshold"),
+			CoverageFail:        c.Bool("coverage-fail"),
+			Security:            c.StringSlice("security"),
+			SecurityMaxFindings: c.Int("security-max-findings"),
+			SecurityAttach:      c.Bool("security-attach"),
+			LogFile:             c.String("log-file"),
+			LogLines:            c.Int("log-lines"),
+			MessageFromStdin:    c.Bool("message-from-stdin"),
+			CodeBlock:           c.Bool("code-block"),
+			CodeLanguage:        c.String("code-language"),
+			StdinFilename:       c.String("stdin-filename"),
+			RedactEnv:           c.StringSlice("redact-env"),
+			RedactRegex:         c.StringSlice("redact-regex"),
+			AllowedHosts:        c.StringSlice("allowed-hosts"),
+			Rules:               rules,
+			DryRun:              c.Bool("dry-run"),
+			Drone:               c.Bool("drone") || c.String("ci.environment") == "woodpecker",
+			GitHub:              c.Bool("github"),
+			Debug:               c.Bool("debug"),
 		},
 		Payload: Payload{
 			Wait:      c.Bool("wait"),
@@ -305,9 +693,42 @@ func run(c *cli.Context) error {
 		},
 	}
 
+	return plugin, nil
+}
+
+func run(c *cli.Context) error {
+	plugin, err := newPlugin(c)
+	if err != nil {
+		return err
+	}
+
 	if plugin.Config.Debug {
-		_ = godump.Dump(plugin)
+		_ = godump.Dump(plugin.Redacted())
 	}
 
 	return plugin.Exec(c.Context)
 }
+
+// validate checks the configured webhooks without posting.
+func validate(c *cli.Context) error {
+	plugin, err := newPlugin(c)
+	if err != nil {
+		return err
+	}
+
+	return plugin.Validate(c.Context, os.Stdout)
+}
+
+// render previews the messages against a fixture context.
+func render(c *cli.Context) error {
+	plugin, err := newPlugin(c)
+	if err != nil {
+		return err
+	}
+
+	if err := loadContext(c.String("context"), &plugin); err != nil {
+		return err
+	}
+
+	return plugin.Render(os.Stdout, c.StringSlice("template"))
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+// pluginFromArgs builds the plugin from command line arguments the way the
+// binary does.
+func pluginFromArgs(t *testing.T, args ...string) Plugin {
+	t.Helper()
+	var plugin Plugin
+	app := &cli.App{
+		Flags: flags(),
+		Action: func(c *cli.Context) error {
+			p, err := newPlugin(c)
+			plugin = p
+			return err
+		},
+	}
+	assert.NoError(t, app.Run(append([]string{"drone-discord"}, args...)))
+	return plugin
+}
+
+func TestStatusMessageFlags(t *testing.T) {
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+
+	plugin := pluginFromArgs(t,
+		"--webhook-id", "1", "--webhook-token", "token", "--dry-run",
+		"--build.status", "success", "--build.number", "7",
+		"--mode", "plain", "--message-success", "build {{build.number}} passed",
+		"--message-failure", "build failed", "--color-failure", "#ff0000",
+	)
+	assert.Equal(t, ModePlain, plugin.Config.Mode)
+	assert.Equal(t, map[string]StatusMessage{
+		StatusSuccess: {Message: []string{"build {{build.number}} passed"}},
+		StatusFailure: {Message: []string{"build failed"}, Color: "#ff0000"},
+	}, plugin.Config.StatusMessages)
+
+	assert.NoError(t, plugin.Exec(context.Background()))
+	assert.Contains(t, out.String(), `"content":"build 7 passed"`)
+	assert.NotContains(t, out.String(), `"embeds":[{`)
+}
+
+func TestTemplateFlags(t *testing.T) {
+	dir := t.TempDir()
+	partials := filepath.Join(dir, "templates")
+	assert.NoError(t, os.MkdirAll(partials, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(partials, "header.hbs"), []byte("**{{repo.name}}**"), 0o600))
+	file := filepath.Join(dir, "message.hbs")
+	assert.NoError(t, os.WriteFile(file, []byte("{{> header}} #{{build.number}}"), 0o600))
+
+	plugin := pluginFromArgs(t,
+		"--repo.name", "go-hello", "--build.number", "3",
+		"--template-file", file, "--template-dir", partials,
+	)
+	assert.Equal(t, []string{file}, plugin.Config.TemplateFile)
+	assert.Equal(t, partials, plugin.Config.TemplateDir)
+
+	var out bytes.Buffer
+	assert.NoError(t, plugin.Render(&out, nil))
+	assert.Equal(t, "**go-hello** #3\n", out.String())
+
+	assert.NoError(t, os.WriteFile(file, []byte("line one\n{{/if}}\n"), 0o600))
+	plugin = pluginFromArgs(t, "--template-file", file)
+	err := plugin.Render(&out, nil)
+	assert.ErrorContains(t, err, "invalid template file "+file+": Parse error on line 2")
+}
+
+func TestTemplateEngineFlag(t *testing.T) {
+	plugin := pluginFromArgs(t,
+		"--build.number", "9", "--template-engine", "go",
+		"--message", `{{ .Build.Number }} {{ upper "ok" }}`,
+	)
+	assert.Equal(t, EngineGo, plugin.Config.TemplateEngine)
+
+	var out bytes.Buffer
+	assert.NoError(t, plugin.Render(&out, nil))
+	assert.Equal(t, "9 OK\n", out.String())
+}
+
+func TestLocaleFlag(t *testing.T) {
+	plugin := pluginFromArgs(t,
+		"--locale", "de", "--build.event", "push",
+		"--commit.author", "appleboy", "--commit.branch", "main",
+	)
+	assert.Equal(t, "de", plugin.Config.Locale)
+	assert.Equal(t, plugin.tr("pushed_to", "appleboy", "main"), plugin.Template().Description)
+	assert.NotContains(t, plugin.Template().Description, "pushed to")
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -23,8 +24,13 @@ const (
 	DroneIconURL = "https://c1.staticflickr.com/5/4236/34957940160_435d83114f_z.jpg"
 	// DroneDesc default drone description
 	DroneDesc = "Powered by Drone Discord Plugin"
+	// TimeLayout is the layout used to display build timestamps.
+	TimeLayout = "2006-01-02 15:04:05 MST"
 )
 
+// now returns the current time, overridden in tests.
+var now = time.Now
+
 type (
 	// GitHub information.
 	GitHub struct {
@@ -52,6 +58,21 @@ type (
 		Avatar  string
 		Email   string
 		Message string
+		Before  string
+		After   string
+
+		List         []CommitEntry
+		ChangedFiles []string
+		Stat         DiffStat
+	}
+
+	// CommitEntry is a commit included in the push.
+	CommitEntry struct {
+		Sha      string
+		ShortSha string
+		Author   string
+		Subject  string
+		Body     string
 	}
 
 	Source struct {
@@ -65,23 +86,72 @@ type (
 		Number   int
 		Status   string
 		Link     string
+		Created  int64
 		Started  int64
 		Finished int64
 		PR       string
 		DeployTo string
+		Cron     string
+
+		PrevStatus string
+		Transition string
 	}
 
 	// Config for the plugin.
 	Config struct {
-		webhookURL   string
-		WebhookID    string
-		WebhookToken string
-		Color        string
-		Message      []string
-		File         []string
-		Drone        bool
-		GitHub       bool
-		Debug        bool
+		webhookURL          string
+		WebhookID           string
+		WebhookToken        string
+		Color               string
+		Mode                string
+		Message             []string
+		StatusMessages      map[string]StatusMessage
+		TemplateFile        []string
+		TemplateDir         string
+		TemplateEngine      string
+		Locale              string
+		Partials            map[string]string
+		File                []string
+		NotifyOn            string
+		StateFile           string
+		Workspace           string
+		MaxCommits          int
+		Diffstat            bool
+		IgnorePaths         []string
+		Changelog           bool
+		ChangelogAttach     bool
+		JUnit               []string
+		JUnitMaxFailures    int
+		JUnitAttach         bool
+		GoTest              []string
+		GoTestMaxFailures   int
+		GoTestLines         int
+		GoTestAttach        bool
+		Coverage            []string
+		CoverageBaseline    string
+		CoverageThreshold   float64
+		CoverageFail        bool
+		Security            []string
+		SecurityMaxFindings int
+		SecurityAttach      bool
+		LogFile             string
+		LogLines            int
+		MessageFromStdin    bool
+		CodeBlock           bool
+		CodeLanguage        string
+		StdinFilename       string
+		RedactEnv           []string
+		RedactRegex         []string
+		AllowedHosts        []string
+		ThreadID            string
+		Webhooks            []Webhook
+		MaxConcurrency      int
+		FailPolicy          string
+		Rules               []Rule
+		DryRun              bool
+		Drone               bool
+		GitHub              bool
+		Debug               bool
 	}
 
 	// EmbedFooterObject for Embed Footer Structure.
@@ -99,8 +169,9 @@ type (
 
 	// EmbedFieldObject for Embed Field Structure
 	EmbedFieldObject struct {
-		Name  string `json:"name"`
-		Value string `json:"value"`
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Inline bool   `json:"inline,omitempty"`
 	}
 
 	// EmbedObject is for Embed Structure
@@ -109,6 +180,7 @@ type (
 		Description string             `json:"description"`
 		URL         string             `json:"url"`
 		Color       int                `json:"color"`
+		Timestamp   string             `json:"timestamp,omitempty"`
 		Footer      EmbedFooterObject  `json:"footer"`
 		Author      EmbedAuthorObject  `json:"author"`
 		Fields      []EmbedFieldObject `json:"fields"`
@@ -126,25 +198,92 @@ type (
 
 	// Plugin values.
 	Plugin struct {
-		GitHub     GitHub
-		Repo       Repo
-		Build      Build
-		Source     Source
-		Config     Config
-		Payload    Payload
-		Commit     Commit
-		httpClient *http.Client
+		GitHub    GitHub
+		Repo      Repo
+		Build     Build
+		Source    Source
+		Config    Config
+		Payload   Payload
+		Commit    Commit
+		Changelog Changelog
+
+		reports      []report
+		attachments  []attachment
+		logTail      string
+		stdinMessage string
+		// templateFiles maps templates read from files to their path.
+		templateFiles map[string]string
+		redactor      *redactor
+		httpClient    *http.Client
+	}
+
+	// attachment is generated content uploaded as a file.
+	attachment struct {
+		Name    string
+		Content []byte
 	}
 )
 
 func (c *Config) validate() error {
-	if c.webhookURL != "" {
-		if _, err := url.Parse(c.webhookURL); err != nil {
-			return fmt.Errorf("invalid webhook url: %w", err)
+	switch c.NotifyOn {
+	case "", NotifyAlways, NotifyChanges:
+	default:
+		return fmt.Errorf("invalid notify-on value: %s", c.NotifyOn)
+	}
+
+	modes := []string{c.Mode}
+	for _, m := range c.StatusMessages {
+		modes = append(modes, m.Mode)
+	}
+	for _, mode := range modes {
+		switch mode {
+		case "", ModeEmbed, ModePlain:
+		default:
+			return fmt.Errorf("invalid mode value: %s", mode)
+		}
+	}
+
+	switch c.TemplateEngine {
+	case "", EngineHandlebars, EngineGo:
+	default:
+		return fmt.Errorf("invalid template engine: %s", c.TemplateEngine)
+	}
+
+	switch c.FailPolicy {
+	case "", FailAny, FailAll:
+	default:
+		return fmt.Errorf("invalid fail-policy value: %s", c.FailPolicy)
+	}
+
+	if c.stdinReaders() > 1 {
+		return errors.New("stdin can only be read by one of message-from-stdin, log-file and file")
+	}
+
+	for i := range c.Webhooks {
+		if err := c.Webhooks[i].validate(c.AllowedHosts); err != nil {
+			return fmt.Errorf("invalid webhook #%d: %w", i+1, err)
 		}
+	}
+
+	for i := range c.Rules {
+		if err := c.Rules[i].validate(c.AllowedHosts); err != nil {
+			return fmt.Errorf("invalid rule %s: %w", c.Rules[i].label(i), err)
+		}
+	}
+
+	if !c.needsDefaultWebhook() {
+		return nil
+	}
+
+	w, ok := c.defaultWebhook()
+	if !ok && len(c.Webhooks) > 0 {
 		return nil
 	}
 
+	if c.webhookURL != "" {
+		return w.validate(c.AllowedHosts)
+	}
+
 	var missingFields []string
 	if c.WebhookID == "" {
 		missingFields = append(missingFields, "WebhookID")
@@ -155,19 +294,55 @@ func (c *Config) validate() error {
 	if len(missingFields) > 0 {
 		return fmt.Errorf("missing discord config: %s", strings.Join(missingFields, ", "))
 	}
-	return nil
+	return validateCredentials(c.WebhookID, c.WebhookToken)
+}
+
+// needsDefaultWebhook reports whether the configured webhook is used,
+// which is not the case when every routing rule sets its own webhooks.
+func (c *Config) needsDefaultWebhook() bool {
+	if len(c.Rules) == 0 {
+		return true
+	}
+	for _, r := range c.Rules {
+		if len(r.Webhooks) == 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // Get WebhookURL
 func (c *Config) GetWebhookURL() string {
-	if c.webhookURL != "" {
-		return c.webhookURL
+	webhookURL := c.webhookURL
+	if webhookURL == "" {
+		webhookURL = fmt.Sprintf("https://discord.com/api/webhooks/%s/%s", c.WebhookID, c.WebhookToken)
+	}
+	if c.ThreadID == "" {
+		return webhookURL
+	}
+
+	sep := "?"
+	if strings.Contains(webhookURL, "?") {
+		sep = "&"
 	}
-	return fmt.Sprintf("https://discord.com/api/webhooks/%s/%s", c.WebhookID, c.WebhookToken)
+	return webhookURL + sep + "thread_id=" + url.QueryEscape(c.ThreadID)
 }
 
 func templateMessage(t string, plugin Plugin) (string, error) {
-	return template.RenderTrim(t, plugin)
+	if plugin.Config.engine() == EngineGo {
+		return renderGoTemplate(t, plugin)
+	}
+
+	if len(plugin.Config.Partials) == 0 {
+		return template.RenderTrim(t, plugin)
+	}
+
+	tpl, err := parseTemplate(t, plugin.Config.Partials)
+	if err != nil {
+		return "", err
+	}
+	out, err := tpl.Exec(plugin)
+	return strings.Trim(out, " \n"), err
 }
 
 // Creates a new file upload http request with optional extra params
@@ -181,17 +356,23 @@ func fileUploadRequest(ctx context.Context, uri string, params map[string]string
 	}
 	defer file.Close()
 
+	return uploadRequest(ctx, uri, params, paramName, filepath.Base(path), file)
+}
+
+// Creates a new upload http request streaming the content as a file named
+// filename, with optional extra params
+func uploadRequest(ctx context.Context, uri string, params map[string]string, paramName, filename string, content io.Reader) (*http.Request, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
 	// Add file
-	part, err := writer.CreateFormFile(paramName, filepath.Base(path))
+	part, err := writer.CreateFormFile(paramName, filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create form file: %w", err)
 	}
 
 	// Stream file content
-	if _, err = io.Copy(part, file); err != nil {
+	if _, err = io.Copy(part, content); err != nil {
 		return nil, fmt.Errorf("failed to write file content: %w", err)
 	}
 
@@ -216,31 +397,130 @@ func fileUploadRequest(ctx context.Context, uri string, params map[string]string
 	return req, nil
 }
 
+// newHTTPClient returns the client used for all Discord requests.
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: 15 * time.Second,
+	}
+}
+
 // Exec executes the plugin.
 func (p *Plugin) Exec(ctx context.Context) error {
 	// init http client
-	p.httpClient = &http.Client{
-		Timeout: 15 * time.Second,
+	p.httpClient = newHTTPClient()
+	if p.Config.DryRun {
+		// print the requests instead of sending them
+		p.httpClient.Transport = &dryRunTransport{w: stdout}
 	}
 
 	if err := p.Config.validate(); err != nil {
 		return fmt.Errorf("failed to validate config: %w", err)
 	}
 
+	r, err := newRedactor(&p.Config)
+	if err != nil {
+		return err
+	}
+	p.redactor = r
+
+	if err := p.resolveTransition(); err != nil {
+		return err
+	}
+
+	if err := p.loadTemplates(); err != nil {
+		return err
+	}
+
+	if err := p.applyStatusMessage(); err != nil {
+		return err
+	}
+
+	if err := p.validateTemplates(); err != nil {
+		return err
+	}
+
+	p.loadCommits(ctx)
+	p.loadChangelog(ctx)
+	p.loadDiffStat(ctx)
+
+	if err := p.loadGoTest(); err != nil {
+		return err
+	}
+
+	if err := p.loadJUnit(); err != nil {
+		return err
+	}
+
+	if err := p.loadCoverage(); err != nil {
+		return err
+	}
+
+	if err := p.loadSecurity(); err != nil {
+		return err
+	}
+
+	if err := p.loadStdin(); err != nil {
+		return err
+	}
+
+	if err := p.loadLog(); err != nil {
+		return err
+	}
+
+	if !p.skipNotification() && !p.onlyIgnoredPaths(ctx) {
+		if err := p.handleWebhooks(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := p.saveState(); err != nil {
+		return err
+	}
+
+	return p.reportErr()
+}
+
+// handleWebhooks sends the notification to every destination, routed by
+// the rules when configured.
+func (p *Plugin) handleWebhooks(ctx context.Context) error {
+	var targets []Plugin
+	if len(p.Config.Rules) > 0 {
+		targets = p.ruleTargets(ctx)
+	} else {
+		for _, w := range p.Config.destinations() {
+			targets = append(targets, p.forWebhook(w))
+		}
+	}
+
+	return p.fanOut(ctx, targets)
+}
+
+// notify sends the configured messages and files.
+func (p *Plugin) notify(ctx context.Context) error {
 	if err := p.handleMessages(ctx); err != nil {
 		return err
 	}
 
-	if err := p.handleFiles(ctx); err != nil {
+	if err := p.handleLog(ctx); err != nil {
 		return err
 	}
 
-	return nil
+	return p.handleFiles(ctx)
 }
 
 // handleMessages sends all configured messages.
 func (p *Plugin) handleMessages(ctx context.Context) error {
-	// 1. Handle empty message (default template)
+	// 1. Handle the message read from stdin, replacing the default template
+	if p.stdinMessage != "" {
+		if err := p.sendContent(ctx, p.stdinChunks()); err != nil {
+			return fmt.Errorf("failed to send stdin message: %w", err)
+		}
+		if len(p.Config.Message) == 0 {
+			return nil
+		}
+	}
+
+	// 2. Handle empty message (default template)
 	if len(p.Config.Message) == 0 {
 		object := p.Template()
 		p.Payload.Embeds = []EmbedObject{object}
@@ -250,7 +530,7 @@ func (p *Plugin) handleMessages(ctx context.Context) error {
 		return nil
 	}
 
-	// 2. Handle custom messages
+	// 3. Handle custom messages
 	for _, m := range p.Config.Message {
 		if m == "" {
 			continue
@@ -261,23 +541,24 @@ func (p *Plugin) handleMessages(ctx context.Context) error {
 			return fmt.Errorf("failed to render template: %w", err)
 		}
 
-		// With color, messages are grouped as embeds
-		if p.Config.Color != "" {
+		// In embed mode, messages are grouped as embeds
+		if p.Config.embedMode() {
 			object := p.DefaultTemplate(txt)
 			p.Payload.Embeds = append(p.Payload.Embeds, object)
 		} else {
-			// Without color, send as plain text immediately
-			p.Payload.Content = txt
-			if err := p.SendMessage(ctx); err != nil {
+			// In plain mode, send as plain text immediately, split at the
+			// content limit
+			if err := p.sendContent(ctx, splitContent(txt, maxContentLength)); err != nil {
 				return fmt.Errorf("failed to send plain text message: %w", err)
 			}
-			// Reset for next message
-			p.Clear()
 		}
 	}
 
-	// 3. Send grouped embeds if any
-	if len(p.Payload.Embeds) > 0 {
+	// 4. Send grouped embeds if any, with the report fields on the last one
+	if n := len(p.Payload.Embeds); n > 0 {
+		last := &p.Payload.Embeds[n-1]
+		last.Fields = append(last.Fields, p.reportFields()...)
+		*last = limitEmbed(*last)
 		if err := p.SendMessage(ctx); err != nil {
 			return fmt.Errorf("failed to send embed messages: %w", err)
 		}
@@ -286,7 +567,7 @@ func (p *Plugin) handleMessages(ctx context.Context) error {
 	return nil
 }
 
-// handleFiles sends all configured files.
+// handleFiles sends all configured files and generated attachments.
 func (p *Plugin) handleFiles(ctx context.Context) error {
 	for _, f := range p.Config.File {
 		if f == "" {
@@ -296,12 +577,16 @@ func (p *Plugin) handleFiles(ctx context.Context) error {
 			return fmt.Errorf("failed to send file %s: %w", f, err)
 		}
 	}
+	for _, a := range p.attachments {
+		if err := p.SendAttachment(ctx, a.Name, a.Content); err != nil {
+			return fmt.Errorf("failed to send attachment %s: %w", a.Name, err)
+		}
+	}
 	return nil
 }
 
-// SendFile upload file to discord
-func (p *Plugin) SendFile(ctx context.Context, file string) error {
-	webhookURL := p.Config.GetWebhookURL()
+// uploadParams returns the extra params of file uploads.
+func (p *Plugin) uploadParams() map[string]string {
 	extraParams := map[string]string{}
 
 	if p.Payload.Username != "" {
@@ -316,20 +601,60 @@ func (p *Plugin) SendFile(ctx context.Context, file string) error {
 		extraParams["tts"] = "true"
 	}
 
+	return extraParams
+}
+
+// SendFile upload file to discord
+func (p *Plugin) SendFile(ctx context.Context, file string) error {
+	// text files are redacted in memory, others are streamed as is
+	text, err := isTextFile(file)
+	if err != nil {
+		return fmt.Errorf("file %s not accessible: %w", file, err)
+	}
+	if text {
+		b, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return fmt.Errorf("file %s not accessible: %w", file, err)
+		}
+		return p.SendAttachment(ctx, filepath.Base(file), b)
+	}
+
 	request, err := fileUploadRequest(
 		ctx,
-		webhookURL,
-		extraParams,
+		p.Config.GetWebhookURL(),
+		p.uploadParams(),
 		"file",
 		file,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create file upload request: %w", err)
+		return fmt.Errorf("failed to create file upload request: %w", redactError(err))
 	}
 
+	return p.upload(request)
+}
+
+// SendAttachment upload content as a file named name to discord
+func (p *Plugin) SendAttachment(ctx context.Context, name string, content []byte) error {
+	request, err := uploadRequest(
+		ctx,
+		p.Config.GetWebhookURL(),
+		p.uploadParams(),
+		"file",
+		name,
+		bytes.NewReader(p.redactor.Bytes(content)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create file upload request: %w", redactError(err))
+	}
+
+	return p.upload(request)
+}
+
+// upload sends the file upload request.
+func (p *Plugin) upload(request *http.Request) error {
 	resp, err := p.httpClient.Do(request)
 	if err != nil {
-		return fmt.Errorf("failed to send file: %w", err)
+		return fmt.Errorf("failed to send file: %w", redactError(err))
 	}
 	defer resp.Body.Close()
 
@@ -352,19 +677,19 @@ func (p *Plugin) SendFile(ctx context.Context, file string) error {
 func (p *Plugin) SendMessage(ctx context.Context) error {
 	webhookURL := p.Config.GetWebhookURL()
 	b := new(bytes.Buffer)
-	if err := json.NewEncoder(b).Encode(p.Payload); err != nil {
+	if err := json.NewEncoder(b).Encode(p.redactor.Payload(p.Payload)); err != nil {
 		return fmt.Errorf("failed to encode payload: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, b)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", redactError(err))
 	}
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return fmt.Errorf("failed to send message: %w", redactError(err))
 	}
 	defer resp.Body.Close()
 
@@ -397,38 +722,130 @@ func (p *Plugin) Template() EmbedObject {
 	var description string
 	switch {
 	case p.Config.GitHub:
-		description = fmt.Sprintf("%s/%s triggered by %s (%s)",
+		description = p.tr("github_triggered",
 			p.Repo.FullName,
 			p.GitHub.Workflow,
 			p.Repo.Namespace,
 			p.GitHub.EventName,
 		)
 	case p.Build.Event == "push":
-		description = fmt.Sprintf("%s pushed to %s", p.Commit.Author, p.Commit.Branch)
+		description = p.tr("pushed_to", p.Commit.Author, p.Commit.Branch)
 	case p.Build.Event == "pull_request":
 		branch := p.Commit.Ref
 		if branch == "" {
 			branch = p.Commit.Branch
 		}
-		description = fmt.Sprintf("%s updated pull request %s", p.Commit.Author, branch)
+		if p.Build.PR != "" && p.Commit.Link != "" {
+			link := string(maskedLink("#"+p.Build.PR, p.Commit.Link))
+			description = p.tr("updated_pull_request_link", p.Commit.Author, link, branch)
+		} else {
+			description = p.tr("updated_pull_request", p.Commit.Author, branch)
+		}
 	case p.Build.Event == "tag":
-		description = fmt.Sprintf("%s pushed tag %s", p.Commit.Author, p.Commit.Branch)
+		description = p.tr("pushed_tag", p.Commit.Author, p.Commit.Branch)
+	case p.Build.Event == "release":
+		description = p.tr("published_release", p.Commit.Author, p.ref())
+	case p.Build.Event == "promote":
+		description = p.tr("promoted", p.Commit.Author, p.Build.Number, p.Build.DeployTo)
+	case p.Build.Event == "rollback":
+		description = p.tr("rolled_back", p.Commit.Author, p.Build.DeployTo, p.Build.Number)
+	case p.Build.Event == "deployment", p.Build.Event == "deploy":
+		description = p.tr("deployed", p.Commit.Author, p.ref(), p.Build.DeployTo)
+	case p.Build.Event == "cron":
+		name := p.Build.Cron
+		if name == "" {
+			name = "cron"
+		}
+		description = p.tr("cron", name, p.Commit.Branch)
+	case p.Build.Event == "custom", p.Build.Event == "manual":
+		description = p.tr("triggered", p.Commit.Author, p.Commit.Branch)
 	}
 
-	return EmbedObject{
-		Title:       p.Commit.Message,
+	title := p.Commit.Message
+	switch p.Build.Transition {
+	case TransitionFixed, TransitionBroken, TransitionStillFailing:
+		if p.Config.NotifyOn == NotifyChanges {
+			title = fmt.Sprintf("[%s] %s", p.tr(p.Build.Transition), title)
+		}
+	}
+
+	// The timing fields come first so they survive the embed limits, which
+	// drop fields from the end.
+	var (
+		fields    []EmbedFieldObject
+		timestamp string
+	)
+	if p.Build.Started > 0 {
+		layout := p.tr("time_layout")
+		finished := p.Build.finishedAt()
+		description = strings.TrimSpace(p.tr("took", description, p.Build.duration()))
+		timestamp = finished.Format(time.RFC3339)
+		if p.Build.Created > 0 {
+			fields = append(fields, EmbedFieldObject{
+				Name:   p.tr("queued"),
+				Value:  time.Unix(p.Build.Created, 0).Format(layout),
+				Inline: true,
+			})
+		}
+		fields = append(fields,
+			EmbedFieldObject{
+				Name:   p.tr("started"),
+				Value:  time.Unix(p.Build.Started, 0).Format(layout),
+				Inline: true,
+			},
+			EmbedFieldObject{
+				Name:   p.tr("finished"),
+				Value:  finished.Format(layout),
+				Inline: true,
+			},
+		)
+	}
+	fields = append(fields, p.reportFields()...)
+	fields = append(fields, p.diffStatFields()...)
+	fields = append(fields, p.commitFields()...)
+	fields = append(fields, p.changelogFields()...)
+
+	return limitEmbed(EmbedObject{
+		Title:       title,
 		Description: description,
 		URL:         p.Build.Link,
 		Color:       p.Color(),
+		Timestamp:   timestamp,
+		Fields:      fields,
 		Author: EmbedAuthorObject{
 			Name:    p.Commit.Author,
 			IconURL: p.Commit.Avatar,
 		},
 		Footer: EmbedFooterObject{
-			Text:    DroneDesc,
+			Text:    p.tr("powered_by"),
 			IconURL: DroneIconURL,
 		},
+	})
+}
+
+// ref returns the tag of the build, or its branch when not built from a tag.
+func (p *Plugin) ref() string {
+	if p.Build.Tag != "" {
+		return p.Build.Tag
 	}
+	return p.Commit.Branch
+}
+
+// finishedAt returns the build finish time. Drone reports zero while the
+// build is still running, in which case the current time is used.
+func (b Build) finishedAt() time.Time {
+	if b.Finished > 0 {
+		return time.Unix(b.Finished, 0)
+	}
+	return now().Truncate(time.Second)
+}
+
+// duration returns how long the build has been running.
+func (b Build) duration() time.Duration {
+	if b.Started <= 0 {
+		return 0
+	}
+	return b.finishedAt().Sub(time.Unix(b.Started, 0))
 }
 
 // Clear reset to default
@@ -448,6 +865,17 @@ func (p *Plugin) Color() int {
 		}
 	}
 
+	// Reports may raise the build status, e.g. failed tests, but never
+	// turn a failed build green
+	status := p.Build.Status
+	severity := reportSeverity[status]
+	if isFailure(status) {
+		severity = reportSeverity[ReportFailure]
+	}
+	if s := p.reportStatus(); reportSeverity[s] > severity {
+		status = s
+	}
+
 	// Predefined status colors
 	statusColors := map[string]int{
 		"success": 0x1ac600, // green
@@ -457,7 +885,7 @@ func (p *Plugin) Color() int {
 		"default": 0xffd930, // yellow
 	}
 
-	if color, exists := statusColors[p.Build.Status]; exists {
+	if color, exists := statusColors[status]; exists {
 		return color
 	}
 	return statusColors["default"]
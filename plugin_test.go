@@ -2,13 +2,51 @@ package main
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// webhookRecorder records the requests received by a fake Discord webhook.
+type webhookRecorder struct {
+	sync.Mutex
+	paths  []string
+	bodies []string
+}
+
+func (r *webhookRecorder) requests() ([]string, []string) {
+	r.Lock()
+	defer r.Unlock()
+	return append([]string(nil), r.paths...), append([]string(nil), r.bodies...)
+}
+
+// testHosts allows the fake webhook servers in webhook URLs.
+var testHosts = []string{"127.0.0.1"}
+
+// newWebhookServer starts a fake Discord webhook answering 204 No Content.
+func newWebhookServer(t *testing.T) (*httptest.Server, *webhookRecorder) {
+	t.Helper()
+	rec := &webhookRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.Lock()
+		rec.paths = append(rec.paths, r.URL.Path)
+		rec.bodies = append(rec.bodies, string(body))
+		rec.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, rec
+}
+
 func TestMissingConfig(t *testing.T) {
 	plugin := Plugin{}
 
@@ -177,3 +215,117 @@ func TestExecWithAllFeatures(t *testing.T) {
 	err = plugin.Exec(context.Background())
 	assert.NoError(t, err)
 }
+
+func TestTemplateBuildTimes(t *testing.T) {
+	started := time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("finished build", func(t *testing.T) {
+		p := Plugin{
+			Commit: Commit{Author: "appleboy", Branch: "master"},
+			Build: Build{
+				Event:    "push",
+				Created:  started.Add(-30 * time.Second).Unix(),
+				Started:  started.Unix(),
+				Finished: started.Add(4*time.Minute + 12*time.Second).Unix(),
+			},
+		}
+
+		object := p.Template()
+		assert.Equal(t, "appleboy pushed to master (took 4m12s)", object.Description)
+		assert.Equal(t, started.Add(4*time.Minute+12*time.Second).Local().Format(time.RFC3339), object.Timestamp)
+		assert.Len(t, object.Fields, 3)
+		assert.Equal(t, "Queued", object.Fields[0].Name)
+		assert.Equal(t, "Started", object.Fields[1].Name)
+		assert.Equal(t, "Finished", object.Fields[2].Name)
+	})
+
+	t.Run("timing fields survive the embed limits", func(t *testing.T) {
+		p := Plugin{
+			Build: Build{
+				Started:  started.Unix(),
+				Finished: started.Add(time.Minute).Unix(),
+			},
+		}
+		fields := make([]EmbedFieldObject, 30)
+		for i := range fields {
+			fields[i] = EmbedFieldObject{Name: "report", Value: strings.Repeat("v", 500)}
+		}
+		p.addReport(report{Fields: fields}, false)
+
+		object := p.Template()
+		assert.Equal(t, "Started", object.Fields[0].Name)
+		assert.Equal(t, "Finished", object.Fields[1].Name)
+	})
+
+	t.Run("running build falls back to now", func(t *testing.T) {
+		defer func(fn func() time.Time) { now = fn }(now)
+		now = func() time.Time { return started.Add(90 * time.Second) }
+
+		p := Plugin{
+			Build: Build{Started: started.Unix()},
+		}
+
+		assert.Equal(t, 90*time.Second, p.Build.duration())
+		object := p.Template()
+		assert.Equal(t, "(took 1m30s)", object.Description)
+		assert.Len(t, object.Fields, 2)
+		assert.Equal(t, started.Add(90*time.Second).Local().Format(TimeLayout), object.Fields[1].Value)
+	})
+
+	t.Run("no timestamps", func(t *testing.T) {
+		p := Plugin{}
+		object := p.Template()
+		assert.Empty(t, object.Timestamp)
+		assert.Empty(t, object.Fields)
+	})
+}
+
+func TestTemplateEventDescriptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    Build
+		commit   Commit
+		expected string
+	}{
+		{"push", Build{Event: "push"}, Commit{Author: "appleboy", Branch: "main"}, "appleboy pushed to main"},
+		{"pull request", Build{Event: "pull_request", PR: "12"}, Commit{Author: "appleboy", Branch: "feature", Link: "https://github.com/appleboy/go-hello/pull/12"}, "appleboy updated pull request [#12](https://github.com/appleboy/go-hello/pull/12) (feature)"},
+		{"tag", Build{Event: "tag"}, Commit{Author: "appleboy", Branch: "v1.0.0"}, "appleboy pushed tag v1.0.0"},
+		{"release", Build{Event: "release", Tag: "v1.0.0"}, Commit{Author: "appleboy", Branch: "main"}, "appleboy published release v1.0.0"},
+		{"promote", Build{Event: "promote", Number: 42, DeployTo: "production"}, Commit{Author: "appleboy"}, "appleboy promoted build #42 to production"},
+		{"rollback", Build{Event: "rollback", Number: 41, DeployTo: "production"}, Commit{Author: "appleboy"}, "appleboy rolled back production to build #41"},
+		{"deployment", Build{Event: "deployment", DeployTo: "staging"}, Commit{Author: "appleboy", Branch: "main"}, "appleboy deployed main to staging"},
+		{"cron", Build{Event: "cron", Cron: "nightly"}, Commit{Branch: "main"}, "cron job nightly triggered a build on main"},
+		{"custom", Build{Event: "custom"}, Commit{Author: "appleboy", Branch: "main"}, "appleboy triggered a build on main"},
+		{"manual", Build{Event: "manual"}, Commit{Author: "appleboy", Branch: "main"}, "appleboy triggered a build on main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Plugin{Build: tt.build, Commit: tt.commit}
+			assert.Equal(t, tt.expected, p.Template().Description)
+		})
+	}
+}
+
+func TestLimitEmbed(t *testing.T) {
+	fields := make([]EmbedFieldObject, 30)
+	for i := range fields {
+		fields[i] = EmbedFieldObject{Name: "name", Value: strings.Repeat("v", 2000)}
+	}
+
+	e := limitEmbed(EmbedObject{Title: strings.Repeat("ü", 300), Fields: fields})
+	assert.Equal(t, maxTitleLength, utf8.RuneCountInString(e.Title))
+	assert.True(t, strings.HasSuffix(e.Title, "…"))
+	assert.Len(t, e.Fields[0].Value, maxFieldValueLength-1+len("…"))
+
+	total := embedLength(e)
+	for _, f := range e.Fields {
+		total += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	assert.LessOrEqual(t, total, maxEmbedLength)
+	assert.Len(t, e.Fields, 5)
+
+	fields = append(fields, EmbedFieldObject{Name: "small", Value: "fits"})
+	e = limitEmbed(EmbedObject{Fields: fields[len(fields)-maxFields:]})
+	assert.Equal(t, "small", e.Fields[len(e.Fields)-1].Name)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// redacted replaces secrets in everything sent to Discord.
+const redacted = "***"
+
+// minSecretLength is the length below which environment values are not
+// redacted, as they would mask ordinary words.
+const minSecretLength = 6
+
+var (
+	// defaultRedactEnv are the environment variable names whose values are
+	// always redacted.
+	defaultRedactEnv = []string{"*_TOKEN", "*_SECRET", "*_PASSWORD", "*_KEY"}
+
+	// tokenPatterns match well-known tokens, keeping their non-secret
+	// prefix.
+	tokenPatterns = []redactPattern{
+		// GitHub tokens
+		{regexp.MustCompile(`\b(gh[pousr]_)[A-Za-z0-9]{36,255}\b`), "${1}" + redacted},
+		{regexp.MustCompile(`\b(github_pat_)[A-Za-z0-9_]{22,255}\b`), "${1}" + redacted},
+		// AWS access key IDs
+		{regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`), "${1}" + redacted},
+		// Discord webhook URLs
+		{regexp.MustCompile(`(https://(?:(?:canary|ptb)\.)?discord(?:app)?\.com/api/(?:v\d+/)?webhooks/\d+/)[\w-]+`), "${1}" + redacted},
+	}
+)
+
+type (
+	// redactor masks secret values and token patterns.
+	redactor struct {
+		values   []string
+		patterns []redactPattern
+	}
+
+	redactPattern struct {
+		re   *regexp.Regexp
+		repl string
+	}
+)
+
+// newRedactor returns a redactor for the webhook tokens, the environment
+// variables matching the default and configured globs and the configured
+// regular expressions.
+func newRedactor(c *Config) (*redactor, error) {
+	r := &redactor{patterns: append([]redactPattern(nil), tokenPatterns...)}
+
+	for _, w := range c.destinations() {
+		r.values = append(r.values, w.Token)
+	}
+	for _, rule := range c.Rules {
+		for _, w := range rule.Webhooks {
+			r.values = append(r.values, w.Token)
+		}
+	}
+
+	globs := append(append([]string(nil), defaultRedactEnv...), c.RedactEnv...)
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		for _, glob := range globs {
+			if ok, err := path.Match(glob, name); err != nil {
+				return nil, fmt.Errorf("invalid redact-env pattern %s: %w", glob, err)
+			} else if ok {
+				r.values = append(r.values, value)
+				break
+			}
+		}
+	}
+
+	for _, expr := range c.RedactRegex {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact-regex %s: %w", expr, err)
+		}
+		r.patterns = append(r.patterns, redactPattern{re, redacted})
+	}
+
+	// redact longer values first so they are not partially replaced
+	values := r.values[:0]
+	for _, v := range r.values {
+		if len(v) >= minSecretLength {
+			values = append(values, v)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	r.values = values
+	return r, nil
+}
+
+// String masks the secrets in the text.
+func (r *redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, redacted)
+	}
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// Bytes masks the secrets in text content, leaving binary content as is.
+func (r *redactor) Bytes(b []byte) []byte {
+	if r == nil || !utf8.Valid(b) || bytes.IndexByte(b, 0) >= 0 {
+		return b
+	}
+	return []byte(r.String(string(b)))
+}
+
+// isTextFile reports whether the file starts like text content, which is
+// redacted in memory before it is uploaded.
+func isTextFile(file string) (bool, error) {
+	f, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	b := make([]byte, 512)
+	n, err := io.ReadFull(f, b)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, err
+	}
+	return bytes.IndexByte(b[:n], 0) < 0, nil
+}
+
+// Payload returns a copy of the payload with the secrets masked in the
+// content and embeds.
+func (r *redactor) Payload(p Payload) Payload {
+	if r == nil {
+		return p
+	}
+	p.Content = r.String(p.Content)
+	if p.Embeds != nil {
+		embeds := make([]EmbedObject, len(p.Embeds))
+		for i, e := range p.Embeds {
+			embeds[i] = r.Embed(e)
+		}
+		p.Embeds = embeds
+	}
+	return p
+}
+
+// Embed returns a copy of the embed with the secrets masked.
+func (r *redactor) Embed(e EmbedObject) EmbedObject {
+	e.Title = r.String(e.Title)
+	e.Description = r.String(e.Description)
+	e.URL = r.String(e.URL)
+	e.Author.Name = r.String(e.Author.Name)
+	e.Footer.Text = r.String(e.Footer.Text)
+	if e.Fields != nil {
+		fields := make([]EmbedFieldObject, len(e.Fields))
+		for i, f := range e.Fields {
+			f.Name = r.String(f.Name)
+			f.Value = r.String(f.Value)
+			fields[i] = f
+		}
+		e.Fields = fields
+	}
+	return e
+}
+
+// webhookTokenPattern matches the token of a webhook URL on any host.
+var webhookTokenPattern = regexp.MustCompile(`(/webhooks/[^/?#]+/)[^/?#]+`)
+
+// redactURL masks the token of the webhook URL.
+func redactURL(u string) string {
+	return webhookTokenPattern.ReplaceAllString(u, "${1}"+redacted)
+}
+
+// redactError masks the webhook token in the URL of url errors, as returned
+// by the HTTP client.
+func redactError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	redactedErr := *urlErr
+	redactedErr.URL = redactURL(urlErr.URL)
+	return &redactedErr
+}
+
+// redactWebhook returns a copy of the webhook without its token.
+func redactWebhook(w Webhook) Webhook {
+	w.URL = redactURL(w.URL)
+	if w.Token != "" {
+		w.Token = redacted
+	}
+	return w
+}
+
+// Redacted returns a copy of the config without webhook tokens, safe to be
+// logged.
+func (c Config) Redacted() Config {
+	if c.WebhookToken != "" {
+		c.WebhookToken = redacted
+	}
+	c.webhookURL = redactURL(c.webhookURL)
+
+	webhooks := make(webhookList, len(c.Webhooks))
+	for i, w := range c.Webhooks {
+		webhooks[i] = redactWebhook(w)
+	}
+	c.Webhooks = webhooks
+
+	rules := make([]Rule, len(c.Rules))
+	for i, r := range c.Rules {
+		r.Webhooks = make(webhookList, len(r.Webhooks))
+		for j, w := range c.Rules[i].Webhooks {
+			r.Webhooks[j] = redactWebhook(w)
+		}
+		rules[i] = r
+	}
+	c.Rules = rules
+	return c
+}
+
+// Redacted returns a copy of the plugin without webhook tokens, safe to be
+// logged.
+func (p Plugin) Redacted() Plugin {
+	p.Config = p.Config.Redacted()
+	p.redactor = nil
+	p.httpClient = nil
+	return p
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadContext reads a JSON or YAML fixture into the plugin. Keys match the
+// plugin fields case-insensitively, such as build.number or repo.name.
+func loadContext(path string, p *Plugin) error {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to read context: %w", err)
+	}
+
+	// YAML is a superset of JSON, and converting it to JSON applies the
+	// case-insensitive field matching of encoding/json
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("invalid context %s: %w", path, err)
+	}
+	if v == nil {
+		return nil
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("invalid context %s: %w", path, err)
+	}
+	if err := json.Unmarshal(j, p); err != nil {
+		return fmt.Errorf("invalid context %s: %w", path, err)
+	}
+	return nil
+}
+
+// Render writes the messages rendered against the plugin context, or the
+// default embed as JSON when no message is configured. Templates given as
+// files replace the configured messages.
+func (p *Plugin) Render(w io.Writer, templates []string) error {
+	if len(templates) > 0 {
+		p.Config.Message = nil
+		p.Config.StatusMessages = nil
+		p.Config.TemplateFile = templates
+	}
+
+	if err := p.loadTemplates(); err != nil {
+		return err
+	}
+
+	if err := p.applyStatusMessage(); err != nil {
+		return err
+	}
+
+	if err := p.validateTemplates(); err != nil {
+		return err
+	}
+
+	if len(p.Config.Message) == 0 {
+		b, err := json.MarshalIndent(p.Template(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode embed: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	for _, m := range p.Config.Message {
+		txt, err := templateMessage(m, *p)
+		if err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
+		}
+		if _, err := fmt.Fprintln(w, txt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadContext(t *testing.T) {
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "fixture.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"repo": {"name": "go-hello"}, "build": {"number": 42, "prevStatus": "failure"}}`), 0o600))
+	yamlFile := filepath.Join(dir, "fixture.yaml")
+	assert.NoError(t, os.WriteFile(yamlFile, []byte("commit:\n  branch: main\n  list:\n    - shortsha: abc1234\n      subject: fix bug\n"), 0o600))
+
+	p := Plugin{Build: Build{Status: "success"}}
+	assert.NoError(t, loadContext(jsonFile, &p))
+	assert.NoError(t, loadContext(yamlFile, &p))
+	assert.Equal(t, "go-hello", p.Repo.Name)
+	assert.Equal(t, 42, p.Build.Number)
+	assert.Equal(t, "failure", p.Build.PrevStatus)
+	assert.Equal(t, "success", p.Build.Status)
+	assert.Equal(t, "main", p.Commit.Branch)
+	assert.Equal(t, []CommitEntry{{ShortSha: "abc1234", Subject: "fix bug"}}, p.Commit.List)
+
+	badFile := filepath.Join(dir, "bad.yaml")
+	assert.NoError(t, os.WriteFile(badFile, []byte("build: {number: many}"), 0o600))
+	assert.ErrorContains(t, loadContext(badFile, &p), "invalid context")
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "msg.hbs")
+	assert.NoError(t, os.WriteFile(tmpl, []byte("**{{repo.name}}** #{{build.number}} {{#success build.status}}passed{{/success}}"), 0o600))
+
+	p := Plugin{
+		Repo:   Repo{Name: "go-hello"},
+		Build:  Build{Number: 42, Status: "success"},
+		Config: Config{Message: []string{"ignored"}},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, p.Render(&out, []string{tmpl}))
+	assert.Equal(t, "**go-hello** #42 passed\n", out.String())
+
+	p = Plugin{
+		Build:  Build{Status: "failure"},
+		Commit: Commit{Author: "appleboy", Branch: "main", Message: "fix bug"},
+	}
+	out.Reset()
+	assert.NoError(t, p.Render(&out, nil))
+	assert.Contains(t, out.String(), `"title": "fix bug"`)
+	assert.Contains(t, out.String(), `"color": 16724530`)
+
+	assert.NoError(t, os.WriteFile(tmpl, []byte("{{#if}}"), 0o600))
+	assert.ErrorContains(t, p.Render(&out, []string{tmpl}), "invalid template file "+tmpl)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Report statuses, from best to worst.
+const (
+	ReportSuccess = "success"
+	ReportWarning = "warning"
+	ReportFailure = "failure"
+)
+
+var reportSeverity = map[string]int{
+	"":            0,
+	ReportSuccess: 1,
+	ReportWarning: 2,
+	ReportFailure: 3,
+}
+
+// report summarizes a build artifact such as a test or coverage report.
+type report struct {
+	Fields []EmbedFieldObject
+	Status string
+	Files  []string
+	// Err fails the plugin once the notification is sent.
+	Err error
+}
+
+// addReport adds the report fields to the messages and queues its files
+// for upload when attach is set.
+func (p *Plugin) addReport(r report, attach bool) {
+	if attach {
+		p.Config.File = append(p.Config.File, r.Files...)
+	}
+	p.reports = append(p.reports, r)
+}
+
+// reportFields returns the fields of all reports.
+func (p *Plugin) reportFields() []EmbedFieldObject {
+	var fields []EmbedFieldObject
+	for _, r := range p.reports {
+		fields = append(fields, r.Fields...)
+	}
+	return fields
+}
+
+// reportStatus returns the worst status of all reports.
+func (p *Plugin) reportStatus() string {
+	status := ""
+	for _, r := range p.reports {
+		if reportSeverity[r.Status] > reportSeverity[status] {
+			status = r.Status
+		}
+	}
+	return status
+}
+
+// reportErr returns the errors of all reports.
+func (p *Plugin) reportErr() error {
+	var errs []error
+	for _, r := range p.reports {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// expandGlobs returns the files matching the glob patterns. In addition to
+// filepath.Match syntax, "**" matches any number of directories.
+func expandGlobs(patterns []string) ([]string, error) {
+	seen := map[string]bool{}
+	var files []string
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		if !strings.Contains(pattern, "**") {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range matches {
+				add(m)
+			}
+			continue
+		}
+
+		pattern = filepath.ToSlash(pattern)
+		root := pattern[:strings.Index(pattern, "**")]
+		root = strings.TrimSuffix(root, "/")
+		if root == "" {
+			root = "."
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			name := filepath.ToSlash(path)
+			if !d.IsDir() && matchGlob(pattern, strings.TrimPrefix(name, "./")) {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
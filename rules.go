@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// stringList accepts either a single string or a list of strings.
+	stringList []string
+
+	// Rule routes notifications based on the build information. All
+	// conditions set on a rule must match, any value of a condition may
+	// match. Branch, tag and path conditions accept glob patterns.
+	Rule struct {
+		Name     string     `yaml:"name"`
+		Branch   stringList `yaml:"branch"`
+		Event    stringList `yaml:"event"`
+		Status   stringList `yaml:"status"`
+		Tag      stringList `yaml:"tag"`
+		DeployTo stringList `yaml:"deploy_to"`
+		Paths    stringList `yaml:"paths"`
+
+		Webhooks webhookList `yaml:"webhooks"`
+		Message  stringList  `yaml:"message"`
+		Color    string      `yaml:"color"`
+	}
+
+	// ruleMatch is the result of evaluating a rule.
+	ruleMatch struct {
+		Rule    Rule
+		Label   string
+		Matched bool
+		Reason  string
+	}
+)
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = stringList{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// parseRules loads routing rules from a file path or an inline YAML or
+// JSON document.
+func parseRules(s string) ([]Rule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var rules []Rule
+	if err := unmarshalSetting(s, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *Rule) validate(allowedHosts []string) error {
+	for i := range r.Webhooks {
+		if err := r.Webhooks[i].validate(allowedHosts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// label returns the rule name or its position in the rule list.
+func (r *Rule) label(i int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// match evaluates the rule and returns the reason when it does not match.
+func (r *Rule) match(ctx context.Context, p *Plugin) (bool, string) {
+	conditions := []struct {
+		name     string
+		patterns stringList
+		value    string
+		glob     bool
+	}{
+		{"branch", r.Branch, p.Commit.Branch, true},
+		{"event", r.Event, p.Build.Event, false},
+		{"status", r.Status, p.Build.Status, false},
+		{"tag", r.Tag, p.Build.Tag, true},
+		{"deploy_to", r.DeployTo, p.Build.DeployTo, false},
+	}
+
+	for _, c := range conditions {
+		if len(c.patterns) == 0 {
+			continue
+		}
+		if !matchAny(c.patterns, c.value, c.glob) {
+			return false, fmt.Sprintf("%s %q does not match %s", c.name, c.value, strings.Join(c.patterns, ", "))
+		}
+	}
+
+	if len(r.Paths) > 0 {
+		files, err := p.changedFiles(ctx)
+		if err != nil {
+			return false, fmt.Sprintf("changed files unavailable: %v", err)
+		}
+		matched := false
+		for _, f := range files {
+			if matchAny(r.Paths, f, true) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, "no changed file matches paths " + strings.Join(r.Paths, ", ")
+		}
+	}
+
+	return true, "all conditions match"
+}
+
+// matchAny reports whether the value matches any of the patterns.
+func matchAny(patterns []string, value string, glob bool) bool {
+	for _, pattern := range patterns {
+		if glob && matchGlob(pattern, value) {
+			return true
+		}
+		if !glob && pattern == value {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash separated name against a glob pattern. In
+// addition to path.Match syntax, "**" matches any number of directories.
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "**") {
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchRules evaluates all routing rules against the build.
+func (p *Plugin) matchRules(ctx context.Context) []ruleMatch {
+	matches := make([]ruleMatch, 0, len(p.Config.Rules))
+	for i, r := range p.Config.Rules {
+		ok, reason := r.match(ctx, p)
+		matches = append(matches, ruleMatch{
+			Rule:    r,
+			Label:   r.label(i),
+			Matched: ok,
+			Reason:  reason,
+		})
+	}
+	return matches
+}
+
+// explainRules logs which routing rules match the build and why.
+func explainRules(matches []ruleMatch) {
+	for _, m := range matches {
+		result := "skipped"
+		if m.Matched {
+			result = "matched"
+		}
+		log.Printf("rule %s %s: %s", m.Label, result, m.Reason)
+	}
+}
+
+// withRule returns a copy of the plugin using the rule message and color.
+func (p *Plugin) withRule(r Rule) Plugin {
+	target := *p
+	if len(r.Message) > 0 {
+		target.Config.Message = r.Message
+	}
+	if r.Color != "" {
+		target.Config.Color = r.Color
+	}
+	return target
+}
+
+// ruleTargets returns the notifications to send for every matching rule.
+// Rules without webhooks use the configured webhooks.
+func (p *Plugin) ruleTargets(ctx context.Context) []Plugin {
+	matches := p.matchRules(ctx)
+	if p.Config.Debug || p.Config.DryRun {
+		explainRules(matches)
+	}
+
+	var targets []Plugin
+	for _, m := range matches {
+		if !m.Matched {
+			continue
+		}
+
+		webhooks := []Webhook(m.Rule.Webhooks)
+		if len(webhooks) == 0 {
+			webhooks = p.Config.destinations()
+		}
+
+		rule := p.withRule(m.Rule)
+		for _, w := range webhooks {
+			targets = append(targets, rule.forWebhook(w))
+		}
+	}
+	return targets
+}
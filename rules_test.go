@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := parseRules(`
+- name: releases
+  tag: v*
+  webhooks: https://discord.com/api/webhooks/1/a
+- branch: [main, release/**]
+  status: failure
+  message: "build {{build.number}} failed"
+`)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+	assert.Equal(t, stringList{"v*"}, rules[0].Tag)
+	assert.Equal(t, webhookList{{URL: "https://discord.com/api/webhooks/1/a"}}, rules[0].Webhooks)
+	assert.Equal(t, stringList{"main", "release/**"}, rules[1].Branch)
+	assert.Equal(t, "#2", rules[1].label(1))
+
+	rules, err = parseRules(`[{"event": ["push", "tag"], "color": "#ff0000"}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, stringList{"push", "tag"}, rules[0].Event)
+	assert.Equal(t, "#ff0000", rules[0].Color)
+
+	_, err = parseRules("{")
+	assert.Error(t, err)
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		expected      bool
+	}{
+		{"main", "main", true},
+		{"release/*", "release/v1", true},
+		{"release/*", "release/v1/hotfix", false},
+		{"release/**", "release/v1/hotfix", true},
+		{"**/*.md", "README.md", true},
+		{"**/*.md", "docs/guide/index.md", true},
+		{"docs/**", "src/main.go", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, matchGlob(tt.pattern, tt.name), "%s %s", tt.pattern, tt.name)
+	}
+}
+
+func TestRuleMatch(t *testing.T) {
+	p := &Plugin{
+		Commit: Commit{Branch: "main", ChangedFiles: []string{"docs/index.md"}},
+		Build:  Build{Event: "push", Status: "failure"},
+	}
+
+	ok, _ := (&Rule{Branch: stringList{"main"}, Status: stringList{"failure"}}).match(context.Background(), p)
+	assert.True(t, ok)
+
+	ok, reason := (&Rule{Event: stringList{"tag"}}).match(context.Background(), p)
+	assert.False(t, ok)
+	assert.Equal(t, `event "push" does not match tag`, reason)
+
+	ok, _ = (&Rule{Paths: stringList{"src/**"}}).match(context.Background(), p)
+	assert.False(t, ok)
+
+	ok, _ = (&Rule{Paths: stringList{"**/*.md"}}).match(context.Background(), p)
+	assert.True(t, ok)
+}
+
+func TestExecWithRules(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	plugin := Plugin{
+		Commit: Commit{Branch: "main"},
+		Build:  Build{Event: "push", Status: "failure"},
+		Config: Config{
+			AllowedHosts: testHosts,
+			Rules: []Rule{
+				{
+					Name:     "ops",
+					Status:   stringList{"failure"},
+					Webhooks: webhookList{{URL: srv.URL + "/api/webhooks/1/ops"}},
+					Message:  stringList{"build failed on {{commit.branch}}"},
+				},
+				{
+					Name:     "releases",
+					Event:    stringList{"tag"},
+					Webhooks: webhookList{{URL: srv.URL + "/api/webhooks/2/releases"}},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	paths, bodies := rec.requests()
+	assert.Equal(t, []string{"/api/webhooks/1/ops"}, paths)
+	assert.Contains(t, bodies[0], `"content":"build failed on main"`)
+}
+
+func TestExecWithRulesDryRun(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	plugin := Plugin{
+		Config: Config{
+			DryRun:       true,
+			AllowedHosts: testHosts,
+			Rules: []Rule{
+				{Webhooks: webhookList{{URL: srv.URL + "/api/webhooks/1/token"}}},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+
+	assert.NoError(t, plugin.Exec(context.Background()))
+	paths, _ := rec.requests()
+	assert.Empty(t, paths)
+	assert.Contains(t, out.String(), "POST "+srv.URL+"/api/webhooks/1/***\n")
+}
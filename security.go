@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Finding severities, from worst to best.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+)
+
+var severityRank = map[string]int{
+	SeverityCritical: 0,
+	SeverityHigh:     1,
+	SeverityMedium:   2,
+	SeverityLow:      3,
+}
+
+type (
+	// Finding is a result of a security scan.
+	Finding struct {
+		Severity string
+		RuleID   string
+		Location string
+		Message  string
+	}
+
+	sarifReport struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []sarifRule `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex *int   `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Message   struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+
+	sarifRule struct {
+		ID                   string `json:"id"`
+		DefaultConfiguration struct {
+			Level string `json:"level"`
+		} `json:"defaultConfiguration"`
+		Properties struct {
+			SecuritySeverity string `json:"security-severity"`
+		} `json:"properties"`
+	}
+
+	trivyReport struct {
+		Results []struct {
+			Target          string `json:"Target"`
+			Vulnerabilities []struct {
+				VulnerabilityID  string `json:"VulnerabilityID"`
+				PkgName          string `json:"PkgName"`
+				InstalledVersion string `json:"InstalledVersion"`
+				Severity         string `json:"Severity"`
+				Title            string `json:"Title"`
+			} `json:"Vulnerabilities"`
+			Misconfigurations []struct {
+				ID            string `json:"ID"`
+				Severity      string `json:"Severity"`
+				Title         string `json:"Title"`
+				CauseMetadata struct {
+					StartLine int `json:"StartLine"`
+				} `json:"CauseMetadata"`
+			} `json:"Misconfigurations"`
+			Secrets []struct {
+				RuleID    string `json:"RuleID"`
+				Severity  string `json:"Severity"`
+				Title     string `json:"Title"`
+				StartLine int    `json:"StartLine"`
+			} `json:"Secrets"`
+		} `json:"Results"`
+	}
+)
+
+// normalizeSeverity maps scanner severities to the finding severities,
+// counting unknown severities as low.
+func normalizeSeverity(s string) string {
+	s = strings.ToLower(s)
+	if _, ok := severityRank[s]; ok {
+		return s
+	}
+	return SeverityLow
+}
+
+// scoreSeverity maps a CVSS score to a severity.
+func scoreSeverity(score float64) string {
+	switch {
+	case score >= 9:
+		return SeverityCritical
+	case score >= 7:
+		return SeverityHigh
+	case score >= 4:
+		return SeverityMedium
+	}
+	return SeverityLow
+}
+
+// levelSeverity maps a SARIF level to a severity.
+func levelSeverity(level string) string {
+	switch level {
+	case "error":
+		return SeverityHigh
+	case "warning", "":
+		return SeverityMedium
+	}
+	return SeverityLow
+}
+
+// location formats a file location with an optional line.
+func location(file string, line int) string {
+	if line > 0 {
+		return file + ":" + strconv.Itoa(line)
+	}
+	return file
+}
+
+// parseSecurity reads the findings of a SARIF or Trivy JSON report.
+func parseSecurity(path string) ([]Finding, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		Runs         json.RawMessage `json:"runs"`
+		Results      json.RawMessage `json:"Results"`
+		ArtifactName string          `json:"ArtifactName"`
+	}
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return nil, fmt.Errorf("invalid security report %s: %w", path, err)
+	}
+
+	switch {
+	case probe.Runs != nil:
+		var r sarifReport
+		if err := json.Unmarshal(b, &r); err != nil {
+			return nil, fmt.Errorf("invalid sarif report %s: %w", path, err)
+		}
+		return r.findings(), nil
+	case probe.Results != nil || probe.ArtifactName != "":
+		var r trivyReport
+		if err := json.Unmarshal(b, &r); err != nil {
+			return nil, fmt.Errorf("invalid trivy report %s: %w", path, err)
+		}
+		return r.findings(), nil
+	}
+	return nil, fmt.Errorf("unknown security report format: %s", path)
+}
+
+func (r sarifReport) findings() []Finding {
+	var findings []Finding
+	for _, run := range r.Runs {
+		rules := map[string]sarifRule{}
+		for _, rule := range run.Tool.Driver.Rules {
+			rules[rule.ID] = rule
+		}
+
+		for _, res := range run.Results {
+			rule, ok := rules[res.RuleID]
+			if res.RuleIndex != nil && *res.RuleIndex < len(run.Tool.Driver.Rules) {
+				rule, ok = run.Tool.Driver.Rules[*res.RuleIndex], true
+			}
+
+			f := Finding{RuleID: res.RuleID, Message: firstLine(res.Message.Text)}
+			if f.RuleID == "" {
+				f.RuleID = rule.ID
+			}
+
+			level := res.Level
+			if level == "" && ok {
+				level = rule.DefaultConfiguration.Level
+			}
+			f.Severity = levelSeverity(level)
+			if score, err := strconv.ParseFloat(rule.Properties.SecuritySeverity, 64); err == nil {
+				f.Severity = scoreSeverity(score)
+			}
+
+			if len(res.Locations) > 0 {
+				loc := res.Locations[0].PhysicalLocation
+				f.Location = location(loc.ArtifactLocation.URI, loc.Region.StartLine)
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+func (r trivyReport) findings() []Finding {
+	var findings []Finding
+	for _, res := range r.Results {
+		for _, v := range res.Vulnerabilities {
+			findings = append(findings, Finding{
+				Severity: normalizeSeverity(v.Severity),
+				RuleID:   v.VulnerabilityID,
+				Location: res.Target + " " + v.PkgName + "@" + v.InstalledVersion,
+				Message:  v.Title,
+			})
+		}
+		for _, m := range res.Misconfigurations {
+			findings = append(findings, Finding{
+				Severity: normalizeSeverity(m.Severity),
+				RuleID:   m.ID,
+				Location: location(res.Target, m.CauseMetadata.StartLine),
+				Message:  m.Title,
+			})
+		}
+		for _, s := range res.Secrets {
+			findings = append(findings, Finding{
+				Severity: normalizeSeverity(s.Severity),
+				RuleID:   s.RuleID,
+				Location: location(res.Target, s.StartLine),
+				Message:  s.Title,
+			})
+		}
+	}
+	return findings
+}
+
+// securityReport renders the counts per severity and the worst findings
+// up to the limit.
+func (p *Plugin) securityReport(findings []Finding, limit int) report {
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+
+	r := report{Status: ReportSuccess}
+	value := p.tr("security_none")
+	switch {
+	case counts[SeverityCritical]+counts[SeverityHigh] > 0:
+		r.Status = ReportFailure
+	case len(findings) > 0:
+		r.Status = ReportWarning
+	}
+	if len(findings) > 0 {
+		value = p.tr("security_summary",
+			counts[SeverityCritical], counts[SeverityHigh], counts[SeverityMedium], counts[SeverityLow])
+	}
+	r.Fields = append(r.Fields, EmbedFieldObject{Name: p.tr("security"), Value: value})
+
+	if len(findings) == 0 || limit <= 0 {
+		return r
+	}
+
+	sorted := append([]Finding(nil), findings...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return severityRank[sorted[i].Severity] < severityRank[sorted[j].Severity]
+	})
+
+	lines := make([]string, 0, limit+1)
+	for i, f := range sorted {
+		if i == limit {
+			lines = append(lines, p.tr("more_findings", len(sorted)-limit))
+			break
+		}
+		line := "**" + f.Severity + "** `" + f.RuleID + "`"
+		if f.Location != "" {
+			line += " " + string(escapeMarkdown(f.Location))
+		}
+		if f.Message != "" {
+			line += ": " + truncateText(f.Message, 100)
+		}
+		lines = append(lines, line)
+	}
+	r.Fields = append(r.Fields, EmbedFieldObject{
+		Name:  p.tr("top_findings"),
+		Value: truncateText(strings.Join(lines, "\n"), maxFieldValueLength),
+	})
+	return r
+}
+
+// loadSecurity summarizes the security reports matching the configured
+// globs.
+func (p *Plugin) loadSecurity() error {
+	if len(p.Config.Security) == 0 {
+		return nil
+	}
+
+	files, err := expandGlobs(p.Config.Security)
+	if err != nil {
+		return fmt.Errorf("invalid security report pattern: %w", err)
+	}
+	if len(files) == 0 {
+		log.Printf("no security report found for %s", strings.Join(p.Config.Security, ", "))
+		return nil
+	}
+
+	// a broken report must not stop the notification
+	var (
+		findings []Finding
+		parsed   int
+	)
+	for _, f := range files {
+		found, err := parseSecurity(f)
+		if err != nil {
+			log.Printf("skipping security report: %v", err)
+			continue
+		}
+		findings = append(findings, found...)
+		parsed++
+	}
+	if parsed == 0 {
+		return nil
+	}
+
+	r := p.securityReport(findings, p.Config.SecurityMaxFindings)
+	r.Files = files
+	p.addReport(r, p.Config.SecurityAttach)
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	sarifReportJSON = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "CodeQL", "rules": [
+      {"id": "go/sql-injection", "properties": {"security-severity": "8.8"}},
+      {"id": "go/unused", "defaultConfiguration": {"level": "note"}}
+    ]}},
+    "results": [
+      {"ruleId": "go/unused", "message": {"text": "Unused variable"},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 3}}}]},
+      {"ruleId": "go/sql-injection", "ruleIndex": 0, "level": "error", "message": {"text": "Query built from user input\nmore details"},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "db/query.go"}, "region": {"startLine": 42}}}]}
+    ]
+  }]
+}`
+
+	trivyReportJSON = `{
+  "SchemaVersion": 2,
+  "ArtifactName": "app:latest",
+  "Results": [{
+    "Target": "go.mod",
+    "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2024-0001", "PkgName": "golang.org/x/net", "InstalledVersion": "v0.1.0", "Severity": "CRITICAL", "Title": "HTTP/2 rapid reset"}
+    ]
+  }, {
+    "Target": "Dockerfile",
+    "Misconfigurations": [
+      {"ID": "DS002", "Severity": "MEDIUM", "Title": "Image user should not be root", "CauseMetadata": {"StartLine": 1}}
+    ],
+    "Secrets": [
+      {"RuleID": "github-pat", "Severity": "UNKNOWN", "Title": "GitHub token", "StartLine": 7}
+    ]
+  }]
+}`
+)
+
+func writeSecurity(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "report.json")
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	return file
+}
+
+func TestParseSecurity(t *testing.T) {
+	findings, err := parseSecurity(writeSecurity(t, sarifReportJSON))
+	assert.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{Severity: SeverityLow, RuleID: "go/unused", Location: "main.go:3", Message: "Unused variable"},
+		{Severity: SeverityHigh, RuleID: "go/sql-injection", Location: "db/query.go:42", Message: "Query built from user input"},
+	}, findings)
+
+	findings, err = parseSecurity(writeSecurity(t, trivyReportJSON))
+	assert.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{Severity: SeverityCritical, RuleID: "CVE-2024-0001", Location: "go.mod golang.org/x/net@v0.1.0", Message: "HTTP/2 rapid reset"},
+		{Severity: SeverityMedium, RuleID: "DS002", Location: "Dockerfile:1", Message: "Image user should not be root"},
+		{Severity: SeverityLow, RuleID: "github-pat", Location: "Dockerfile:7", Message: "GitHub token"},
+	}, findings)
+
+	_, err = parseSecurity(writeSecurity(t, `{"foo": 1}`))
+	assert.ErrorContains(t, err, "unknown security report format")
+}
+
+func TestSecurityReport(t *testing.T) {
+	plugin := Plugin{}
+
+	r := plugin.securityReport(nil, 5)
+	assert.Equal(t, ReportSuccess, r.Status)
+	assert.Equal(t, []EmbedFieldObject{{Name: "Security", Value: "No findings"}}, r.Fields)
+
+	r = plugin.securityReport([]Finding{{Severity: SeverityLow, RuleID: "a"}, {Severity: SeverityMedium, RuleID: "b"}}, 1)
+	assert.Equal(t, ReportWarning, r.Status)
+	assert.Equal(t, []EmbedFieldObject{
+		{Name: "Security", Value: "0 critical, 0 high, 1 medium, 1 low"},
+		{Name: "Top findings", Value: "**medium** `b`\nand 1 more"},
+	}, r.Fields)
+}
+
+func TestExecWithSecurity(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	plugin := Plugin{
+		Build: Build{Status: "success"},
+		Config: Config{
+			webhookURL:          srv.URL + "/api/webhooks/1/token",
+			AllowedHosts:        testHosts,
+			Security:            []string{writeSecurity(t, trivyReportJSON)},
+			SecurityMaxFindings: 2,
+			SecurityAttach:      true,
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+	assert.Equal(t, 0xff3232, plugin.Color())
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 2)
+	assert.Contains(t, bodies[0], `{"name":"Security","value":"1 critical, 0 high, 1 medium, 1 low"}`)
+	assert.Contains(t, bodies[0], "**critical** `CVE-2024-0001` go.mod golang.org/x/net@v0.1.0: HTTP/2 rapid reset")
+	assert.Contains(t, bodies[1], `filename="report.json"`)
+}
+
+func TestExecSkipsBrokenSecurity(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	plugin := Plugin{
+		Build: Build{Status: "success"},
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/token",
+			AllowedHosts: testHosts,
+			Security: []string{
+				writeSecurity(t, trivyReportJSON),
+				writeSecurity(t, `{"runs": [`),
+			},
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 1)
+	assert.Contains(t, bodies[0], `{"name":"Security","value":"1 critical, 0 high, 1 medium, 1 low"}`)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Message modes.
+const (
+	// ModeEmbed sends messages as embeds.
+	ModeEmbed = "embed"
+	// ModePlain sends messages as plain text.
+	ModePlain = "plain"
+)
+
+// Status categories used to select per-status messages.
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+	StatusStarted = "started"
+)
+
+// StatusMessage is the message variant used for a build status category.
+type StatusMessage struct {
+	Message []string
+	File    string
+	Color   string
+	Mode    string
+}
+
+// statusCategory maps a build status to its status category.
+func statusCategory(status string) string {
+	switch {
+	case isFailure(status):
+		return StatusFailure
+	case status == "running", status == "pending", status == "started":
+		return StatusStarted
+	default:
+		return StatusSuccess
+	}
+}
+
+// applyStatusMessage replaces the message, color and mode with the variant
+// configured for the build status.
+func (p *Plugin) applyStatusMessage() error {
+	m, ok := p.Config.StatusMessages[statusCategory(p.Build.Status)]
+	if !ok {
+		return nil
+	}
+
+	message := append([]string(nil), m.Message...)
+	if m.File != "" {
+		b, err := os.ReadFile(filepath.Clean(m.File))
+		if err != nil {
+			return fmt.Errorf("failed to read message file: %w", err)
+		}
+		message = append(message, string(b))
+		p.addTemplateFile(string(b), m.File)
+	}
+
+	if len(message) > 0 {
+		p.Config.Message = message
+	}
+	if m.Color != "" {
+		p.Config.Color = m.Color
+	}
+	if m.Mode != "" {
+		p.Config.Mode = m.Mode
+	}
+	return nil
+}
+
+// embedMode reports whether custom messages are sent as embeds. Without an
+// explicit mode, messages are embeds when a color is set.
+func (c *Config) embedMode() bool {
+	if c.Mode == "" {
+		return c.Color != ""
+	}
+	return c.Mode == ModeEmbed
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusCategory(t *testing.T) {
+	assert.Equal(t, StatusSuccess, statusCategory("success"))
+	assert.Equal(t, StatusFailure, statusCategory("failure"))
+	assert.Equal(t, StatusFailure, statusCategory("killed"))
+	assert.Equal(t, StatusStarted, statusCategory("running"))
+}
+
+func TestApplyStatusMessage(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "failure.hbs")
+	assert.NoError(t, os.WriteFile(file, []byte("build {{build.number}} failed"), 0o600))
+
+	p := Plugin{
+		Build: Build{Status: "error"},
+		Config: Config{
+			Message: []string{"default"},
+			StatusMessages: map[string]StatusMessage{
+				StatusSuccess: {Message: []string{"passed"}},
+				StatusFailure: {File: file, Color: "#ff0000", Mode: ModeEmbed},
+			},
+		},
+	}
+
+	assert.NoError(t, p.applyStatusMessage())
+	assert.Equal(t, []string{"build {{build.number}} failed"}, p.Config.Message)
+	assert.Equal(t, "#ff0000", p.Config.Color)
+	assert.True(t, p.Config.embedMode())
+
+	p = Plugin{
+		Build:  Build{Status: "running"},
+		Config: Config{Message: []string{"default"}},
+	}
+	assert.NoError(t, p.applyStatusMessage())
+	assert.Equal(t, []string{"default"}, p.Config.Message)
+}
+
+func TestExecWithStatusMessage(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	plugin := Plugin{
+		Build: Build{Number: 7, Status: "success"},
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/token",
+			AllowedHosts: testHosts,
+			Color:        "#00ff00",
+			StatusMessages: map[string]StatusMessage{
+				StatusSuccess: {Message: []string{"build {{build.number}} passed"}, Mode: ModePlain},
+			},
+		},
+	}
+
+	assert.NoError(t, plugin.Exec(context.Background()))
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 1)
+	assert.Contains(t, bodies[0], `"content":"build 7 passed"`)
+	assert.Contains(t, bodies[0], `"embeds":null`)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// stdinPath is the path reading standard input instead of a file.
+const stdinPath = "-"
+
+// stdinReaders returns the number of settings reading standard input.
+func (c *Config) stdinReaders() int {
+	n := 0
+	if c.MessageFromStdin {
+		n++
+	}
+	if c.LogFile == stdinPath {
+		n++
+	}
+	for _, f := range c.File {
+		if f == stdinPath {
+			n++
+		}
+	}
+	return n
+}
+
+// loadStdin reads the message content or the file upload from standard
+// input.
+func (p *Plugin) loadStdin() error {
+	var files []string
+	for _, f := range p.Config.File {
+		if f != stdinPath {
+			files = append(files, f)
+			continue
+		}
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		name := p.Config.StdinFilename
+		if name == "" {
+			name = "stdin.txt"
+		}
+		p.attachments = append(p.attachments, attachment{Name: name, Content: b})
+	}
+	p.Config.File = files
+
+	if !p.Config.MessageFromStdin {
+		return nil
+	}
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	p.stdinMessage = strings.TrimRight(string(b), "\r\n")
+	return nil
+}
+
+// stdinChunks splits the message read from standard input at the content
+// limit, wrapping each chunk in a code block when configured.
+func (p *Plugin) stdinChunks() []string {
+	if p.Config.CodeBlock || p.Config.CodeLanguage != "" {
+		return splitCodeBlock(p.Config.CodeLanguage, p.stdinMessage)
+	}
+	return splitContent(p.stdinMessage, maxContentLength)
+}
+
+// sendContent sends the content as plain messages split at the content
+// limit.
+func (p *Plugin) sendContent(ctx context.Context, chunks []string) error {
+	for _, c := range chunks {
+		p.Payload.Content = c
+		if err := p.SendMessage(ctx); err != nil {
+			return err
+		}
+		// Reset for next message
+		p.Clear()
+	}
+	return nil
+}
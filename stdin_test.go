@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitContent(t *testing.T) {
+	assert.Equal(t, []string{"abc\n", "def\n", "gh"}, splitContent("abc\ndef\ngh", 5))
+	assert.Equal(t, []string{"abcde", "fg"}, splitContent("abcdefg", 5))
+	assert.Equal(t, []string{"日本", "語"}, splitContent("日本語", 2))
+	assert.Nil(t, splitContent("", 5))
+}
+
+func TestSplitCodeBlock(t *testing.T) {
+	line := strings.Repeat("x", 99) + "\n"
+	chunks := splitCodeBlock("go", strings.Repeat(line, 30))
+	assert.Len(t, chunks, 2)
+	for _, c := range chunks {
+		assert.LessOrEqual(t, len(c), maxContentLength)
+		assert.True(t, strings.HasPrefix(c, "```go\nxxx"))
+		assert.True(t, strings.HasSuffix(c, "x\n```"))
+	}
+}
+
+func TestValidateStdinReaders(t *testing.T) {
+	c := Config{WebhookID: "1", WebhookToken: "token", MessageFromStdin: true, File: []string{"-"}}
+	assert.ErrorContains(t, c.validate(), "stdin can only be read by one of")
+}
+
+func TestExecWithMessageFromStdin(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	stdin = strings.NewReader(strings.Repeat("error: something failed\n", 100))
+	defer func() { stdin = os.Stdin }()
+
+	plugin := Plugin{
+		Build: Build{Status: "failure"},
+		Config: Config{
+			webhookURL:       srv.URL + "/api/webhooks/1/token",
+			AllowedHosts:     testHosts,
+			MessageFromStdin: true,
+			CodeLanguage:     "text",
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 2)
+	for _, b := range bodies {
+		assert.Contains(t, b, `"content":"`+"```"+`text\nerror: something failed\n`)
+		assert.NotContains(t, b, `"embeds":[{`)
+	}
+}
+
+func TestExecWithFileFromStdin(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	stdin = strings.NewReader("report contents")
+	defer func() { stdin = os.Stdin }()
+
+	plugin := Plugin{
+		Build: Build{Status: "success"},
+		Config: Config{
+			webhookURL:    srv.URL + "/api/webhooks/1/token",
+			AllowedHosts:  testHosts,
+			Message:       []string{"done"},
+			Mode:          ModePlain,
+			File:          []string{"-"},
+			StdinFilename: "report.txt",
+		},
+	}
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	_, bodies := rec.requests()
+	assert.Len(t, bodies, 2)
+	assert.Contains(t, bodies[0], `"content":"done"`)
+	assert.Contains(t, bodies[1], `filename="report.txt"`)
+	assert.Contains(t, bodies[1], "report contents")
+}
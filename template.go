@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/mailgun/raymond/v2"
+)
+
+// Template engines.
+const (
+	// EngineHandlebars renders templates with handlebars.
+	EngineHandlebars = "handlebars"
+	// EngineGo renders templates with Go text/template and sprig functions.
+	EngineGo = "go"
+)
+
+// partialExts are the file extensions loaded as partials per engine.
+var partialExts = map[string]map[string]bool{
+	EngineHandlebars: {
+		".hbs":        true,
+		".handlebars": true,
+	},
+	EngineGo: {
+		".tmpl":   true,
+		".gotmpl": true,
+	},
+}
+
+// goFuncs are the functions available to Go templates: sprig, the Discord
+// helpers and Go flavored versions of the drone-template-lib helpers.
+var goFuncs = func() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	for name, fn := range helpers {
+		funcs[name] = fn
+	}
+	// Go templates do not escape HTML, so the markup helpers return plain
+	// strings that can be piped into sprig functions.
+	funcs["discordTime"] = func(timestamp int64, style string) string {
+		return string(discordTime(timestamp, style))
+	}
+	funcs["escapeMarkdown"] = func(s string) string {
+		return string(escapeMarkdown(s))
+	}
+	funcs["codeBlock"] = func(lang, content string) string {
+		return string(codeBlock(lang, content))
+	}
+	funcs["maskedLink"] = func(text, url string) string {
+		return string(maskedLink(text, url))
+	}
+	funcs["mentionUser"] = func(id string) string {
+		return string(mentionUser(id))
+	}
+	funcs["mentionRole"] = func(id string) string {
+		return string(mentionRole(id))
+	}
+	funcs["mentionChannel"] = func(id string) string {
+		return string(mentionChannel(id))
+	}
+	funcs["datetime"] = func(timestamp int64, layout, zone string) string {
+		t := time.Unix(timestamp, 0)
+		if loc, err := time.LoadLocation(zone); err == nil && zone != "" {
+			t = t.In(loc)
+		}
+		return t.Format(layout)
+	}
+	funcs["since"] = func(start int64) string {
+		return now().Truncate(time.Second).Sub(time.Unix(start, 0)).String()
+	}
+	funcs["success"] = func(status string) bool {
+		return status == "success"
+	}
+	funcs["failure"] = isFailure
+	return funcs
+}()
+
+// engine returns the configured template engine.
+func (c *Config) engine() string {
+	if c.TemplateEngine == "" {
+		return EngineHandlebars
+	}
+	return c.TemplateEngine
+}
+
+// loadTemplates reads the template files as messages and the partials of
+// the template directory.
+func (p *Plugin) loadTemplates() error {
+	for _, f := range p.Config.TemplateFile {
+		if f == "" {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Clean(f))
+		if err != nil {
+			return fmt.Errorf("failed to read template file: %w", err)
+		}
+		p.Config.Message = append(p.Config.Message, string(b))
+		p.addTemplateFile(string(b), f)
+	}
+
+	if p.Config.TemplateDir == "" {
+		return nil
+	}
+
+	partials, err := loadPartials(p.Config.TemplateDir, partialExts[p.Config.engine()])
+	if err != nil {
+		return err
+	}
+	if p.Config.Partials == nil {
+		p.Config.Partials = map[string]string{}
+	}
+	for name, source := range partials {
+		p.Config.Partials[name] = source
+	}
+	return nil
+}
+
+// addTemplateFile records the file a template was read from, so errors
+// name the file.
+func (p *Plugin) addTemplateFile(source, path string) {
+	if p.templateFiles == nil {
+		p.templateFiles = map[string]string{}
+	}
+	p.templateFiles[source] = path
+}
+
+// loadPartials reads all partials in the directory. A partial is named
+// after its path relative to the directory without extension.
+func loadPartials(dir string, exts map[string]bool) (map[string]string, error) {
+	partials := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if d.IsDir() || !exts[ext] {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		b, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		partials[filepath.ToSlash(strings.TrimSuffix(rel, ext))] = string(b)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load partials: %w", err)
+	}
+	return partials, nil
+}
+
+// parseTemplate parses a handlebars template and registers the partials.
+func parseTemplate(source string, partials map[string]string) (*raymond.Template, error) {
+	tpl, err := raymond.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	tpl.RegisterPartials(partials)
+	return tpl, nil
+}
+
+// parseGoTemplate parses a Go template with the partials defined as named
+// templates.
+func parseGoTemplate(source string, partials map[string]string) (*template.Template, error) {
+	tpl := template.New("message").Funcs(goFuncs)
+	for name, partial := range partials {
+		if _, err := tpl.New(name).Parse(partial); err != nil {
+			return nil, err
+		}
+	}
+	return tpl.New("message").Parse(source)
+}
+
+// renderGoTemplate renders a Go template against the plugin.
+func renderGoTemplate(source string, plugin Plugin) (string, error) {
+	tpl, err := parseGoTemplate(source, plugin.Config.Partials)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tpl.Execute(&b, plugin); err != nil {
+		return "", err
+	}
+	return strings.Trim(b.String(), " \n"), nil
+}
+
+// checkTemplate parses the template with the configured engine.
+func (c *Config) checkTemplate(source string) error {
+	if c.engine() == EngineGo {
+		_, err := parseGoTemplate(source, c.Partials)
+		return err
+	}
+	_, err := parseTemplate(source, c.Partials)
+	return err
+}
+
+// validateTemplates parses all message templates and partials so syntax
+// errors are reported before anything is sent.
+func (p *Plugin) validateTemplates() error {
+	switch p.Config.engine() {
+	case EngineHandlebars:
+		for name, source := range p.Config.Partials {
+			if _, err := raymond.Parse(source); err != nil {
+				return fmt.Errorf("invalid partial %s: %w", name, err)
+			}
+		}
+	case EngineGo:
+		if _, err := parseGoTemplate("", p.Config.Partials); err != nil {
+			return fmt.Errorf("invalid partial: %w", err)
+		}
+	}
+
+	messages := append([]string(nil), p.Config.Message...)
+	for _, r := range p.Config.Rules {
+		messages = append(messages, r.Message...)
+	}
+	for i, m := range messages {
+		if err := p.Config.checkTemplate(m); err != nil {
+			if path, ok := p.templateFiles[m]; ok {
+				return fmt.Errorf("invalid template file %s: %w", path, err)
+			}
+			return fmt.Errorf("invalid message template #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateFileWithPartials(t *testing.T) {
+	dir := t.TempDir()
+	partials := filepath.Join(dir, "templates")
+	assert.NoError(t, os.MkdirAll(filepath.Join(partials, "common"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(partials, "header.hbs"), []byte("**{{repo.name}}**"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(partials, "common", "footer.hbs"), []byte("by {{commit.author}}"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(partials, "README.md"), []byte("{{#if"), 0o600))
+	file := filepath.Join(dir, "message.hbs")
+	assert.NoError(t, os.WriteFile(file, []byte("{{> header}} build {{build.number}}\n{{> common/footer}}\n"), 0o600))
+
+	p := Plugin{
+		Repo:   Repo{Name: "go-hello"},
+		Commit: Commit{Author: "appleboy"},
+		Build:  Build{Number: 3},
+		Config: Config{TemplateFile: []string{file}, TemplateDir: partials},
+	}
+	assert.NoError(t, p.loadTemplates())
+	assert.NoError(t, p.validateTemplates())
+	assert.Len(t, p.Config.Partials, 2)
+
+	txt, err := templateMessage(p.Config.Message[0], p)
+	assert.NoError(t, err)
+	assert.Equal(t, "**go-hello** build 3\nby appleboy", txt)
+}
+
+func TestValidateTemplatesReportsLine(t *testing.T) {
+	p := Plugin{
+		Config: Config{
+			WebhookID:    "1",
+			WebhookToken: "token",
+			Message:      []string{"line one\n{{#success build.status}}\nok\n{{/failure}}"},
+		},
+	}
+	err := p.Exec(context.Background())
+	assert.ErrorContains(t, err, "invalid message template #1: Parse error on line 4")
+}
+
+func TestGoTemplateEngine(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "header.tmpl"), []byte(`**{{ .Repo.Name }}**`), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "header.hbs"), []byte(`ignored`), 0o600))
+
+	p := Plugin{
+		Repo:   Repo{Name: "go-hello"},
+		Commit: Commit{Sha: "e5e82b5eb3737205c25955dcc3dcacc839b7be52"},
+		Build:  Build{Number: 3, Status: "killed"},
+		Config: Config{TemplateDir: dir, TemplateEngine: EngineGo},
+	}
+	assert.NoError(t, p.loadTemplates())
+	assert.Equal(t, map[string]string{"header": `**{{ .Repo.Name }}**`}, p.Config.Partials)
+
+	txt, err := templateMessage(`{{ template "header" . }} #{{ .Build.Number }}
+{{- if or (eq .Build.Status "failure") (eq .Build.Status "killed") }} {{ statusEmoji .Build.Status }} failed{{ end }}
+{{ shortSha .Commit.Sha | upper }}`, p)
+	assert.NoError(t, err)
+	assert.Equal(t, "**go-hello** #3 🛑 failed\nE5E82B5", txt)
+
+	assert.Error(t, p.Config.checkTemplate("{{ if }}"))
+	assert.NoError(t, p.Config.checkTemplate("{{ .Build.Number }}"))
+}
+
+func TestGoTemplateHelpersPipeIntoSprig(t *testing.T) {
+	p := Plugin{
+		Commit: Commit{Message: "fix *bold*"},
+		Config: Config{TemplateEngine: EngineGo},
+	}
+
+	txt, err := templateMessage(`{{ escapeMarkdown .Commit.Message | upper }} {{ maskedLink "x" "y" | trunc 3 }} {{ mentionRole "1" | quote }}`, p)
+	assert.NoError(t, err)
+	assert.Equal(t, `FIX \*BOLD\* [x] "<@&1>"`, txt)
+}
+
+func TestInvalidTemplateEngine(t *testing.T) {
+	p := Plugin{Config: Config{TemplateEngine: "jinja"}}
+	assert.ErrorContains(t, p.Exec(context.Background()), "invalid template engine: jinja")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Notification modes.
+const (
+	// NotifyAlways sends a notification for every build.
+	NotifyAlways = "always"
+	// NotifyChanges only sends a notification when the build status changed
+	// or the build keeps failing.
+	NotifyChanges = "changes"
+)
+
+// Build status transitions compared to the previous build.
+const (
+	TransitionFixed        = "fixed"
+	TransitionBroken       = "broken"
+	TransitionStillFailing = "still_failing"
+	TransitionStillPassing = "still_passing"
+)
+
+// isFailure reports whether the build status is a failed one.
+func isFailure(status string) bool {
+	switch status {
+	case "failure", "error", "killed":
+		return true
+	}
+	return false
+}
+
+// isFinal reports whether the build status is the result of a finished
+// build, as opposed to running or started.
+func isFinal(status string) bool {
+	return status == "success" || isFailure(status)
+}
+
+// transition compares the previous and current build status. An empty
+// string is returned when the previous status is unknown or the current
+// build is still running.
+func transition(prev, curr string) string {
+	if prev == "" || !isFinal(curr) {
+		return ""
+	}
+
+	switch {
+	case isFailure(prev) && isFailure(curr):
+		return TransitionStillFailing
+	case isFailure(prev):
+		return TransitionFixed
+	case isFailure(curr):
+		return TransitionBroken
+	default:
+		return TransitionStillPassing
+	}
+}
+
+// resolveTransition loads the previous build status, falling back to the
+// state file, and computes the status transition.
+func (p *Plugin) resolveTransition() error {
+	if p.Build.PrevStatus == "" && p.Config.StateFile != "" {
+		b, err := os.ReadFile(filepath.Clean(p.Config.StateFile))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read state file: %w", err)
+		}
+		p.Build.PrevStatus = strings.TrimSpace(string(b))
+	}
+
+	p.Build.Transition = transition(p.Build.PrevStatus, p.Build.Status)
+	return nil
+}
+
+// skipNotification reports whether the notification should be skipped
+// because the build status did not change.
+func (p *Plugin) skipNotification() bool {
+	if p.Config.NotifyOn != NotifyChanges {
+		return false
+	}
+	if p.Build.Transition == TransitionStillPassing {
+		log.Printf("build status is still %s, skipping notification", p.Build.Status)
+		return true
+	}
+	return false
+}
+
+// saveState records the current build status in the state file, except
+// for dry runs and unfinished builds.
+func (p *Plugin) saveState() error {
+	if p.Config.StateFile == "" || p.Config.DryRun || !isFinal(p.Build.Status) {
+		return nil
+	}
+	if err := os.WriteFile(filepath.Clean(p.Config.StateFile), []byte(p.Build.Status+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		prev, curr string
+		expected   string
+	}{
+		{"", "success", ""},
+		{"success", "success", TransitionStillPassing},
+		{"success", "failure", TransitionBroken},
+		{"failure", "success", TransitionFixed},
+		{"error", "killed", TransitionStillFailing},
+		{"failure", "running", ""},
+		{"success", "pending", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prev+"->"+tt.curr, func(t *testing.T) {
+			assert.Equal(t, tt.expected, transition(tt.prev, tt.curr))
+		})
+	}
+}
+
+func TestNotifyOnChangesSkipsUnchangedBuild(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	assert.NoError(t, os.WriteFile(stateFile, []byte("success\n"), 0o600))
+
+	plugin := Plugin{
+		Build: Build{Status: "success"},
+		Config: Config{
+			WebhookID:    "1",
+			WebhookToken: "token",
+			NotifyOn:     NotifyChanges,
+			StateFile:    stateFile,
+		},
+	}
+
+	assert.NoError(t, plugin.Exec(context.Background()))
+	assert.Equal(t, "success", plugin.Build.PrevStatus)
+	assert.Equal(t, TransitionStillPassing, plugin.Build.Transition)
+}
+
+func TestSaveStateKeepsFinalStatus(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	assert.NoError(t, os.WriteFile(stateFile, []byte("failure\n"), 0o600))
+
+	for _, status := range []string{"running", "started", "pending"} {
+		plugin := Plugin{Build: Build{Status: status}, Config: Config{StateFile: stateFile}}
+		assert.NoError(t, plugin.saveState())
+	}
+	b, err := os.ReadFile(stateFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "failure\n", string(b))
+
+	plugin := Plugin{Build: Build{Status: "failure"}, Config: Config{StateFile: stateFile}}
+	assert.NoError(t, plugin.resolveTransition())
+	assert.Equal(t, TransitionStillFailing, plugin.Build.Transition)
+
+	plugin = Plugin{Build: Build{Status: "success"}, Config: Config{StateFile: stateFile}}
+	assert.NoError(t, plugin.saveState())
+	b, err = os.ReadFile(stateFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "success\n", string(b))
+}
+
+func TestTemplateTransitionLabel(t *testing.T) {
+	p := Plugin{
+		Commit: Commit{Message: "fix: flaky test"},
+		Build:  Build{Status: "success", PrevStatus: "failure"},
+		Config: Config{NotifyOn: NotifyChanges},
+	}
+	assert.NoError(t, p.resolveTransition())
+	assert.Equal(t, "[Fixed] fix: flaky test", p.Template().Title)
+
+	p.Config.NotifyOn = NotifyAlways
+	assert.Equal(t, "fix: flaky test", p.Template().Title)
+}
+
+func TestInvalidNotifyOn(t *testing.T) {
+	plugin := Plugin{Config: Config{NotifyOn: "sometimes"}}
+	err := plugin.Exec(context.Background())
+	assert.ErrorContains(t, err, "invalid notify-on value")
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Webhook types returned by Discord.
+var webhookTypes = map[int]string{
+	1: "incoming",
+	2: "channel follower",
+	3: "application",
+}
+
+// WebhookInfo is the webhook object returned by Discord.
+type WebhookInfo struct {
+	ID        string `json:"id"`
+	Type      int    `json:"type"`
+	Name      string `json:"name"`
+	ChannelID string `json:"channel_id"`
+	GuildID   string `json:"guild_id"`
+}
+
+// String describes the webhook without its token.
+func (w WebhookInfo) String() string {
+	typ, ok := webhookTypes[w.Type]
+	if !ok {
+		typ = fmt.Sprintf("type %d", w.Type)
+	}
+	return fmt.Sprintf("webhook %s: name %q, channel %s, guild %s, %s", w.ID, w.Name, w.ChannelID, w.GuildID, typ)
+}
+
+// webhooks returns every configured webhook, including the webhooks of
+// the routing rules, once.
+func (c *Config) webhooks() []Webhook {
+	var webhooks []Webhook
+	seen := map[Webhook]bool{}
+	add := func(w Webhook) {
+		w.ThreadID, w.Username, w.AvatarURL = "", "", ""
+		if !seen[w] {
+			seen[w] = true
+			webhooks = append(webhooks, w)
+		}
+	}
+	if c.needsDefaultWebhook() {
+		for _, w := range c.destinations() {
+			add(w)
+		}
+	}
+	for _, r := range c.Rules {
+		for _, w := range r.Webhooks {
+			add(w)
+		}
+	}
+	return webhooks
+}
+
+// GetWebhook fetches the webhook object from Discord.
+func (p *Plugin) GetWebhook(ctx context.Context) (WebhookInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Config.GetWebhookURL(), nil)
+	if err != nil {
+		return WebhookInfo{}, fmt.Errorf("failed to create request: %w", redactError(err))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return WebhookInfo{}, fmt.Errorf("failed to get webhook: %w", redactError(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return WebhookInfo{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return WebhookInfo{}, errors.New("invalid webhook token")
+	case http.StatusNotFound:
+		return WebhookInfo{}, errors.New("unknown webhook")
+	default:
+		return WebhookInfo{}, fmt.Errorf("failed to get webhook, status code: %d", resp.StatusCode)
+	}
+
+	var info WebhookInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return WebhookInfo{}, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	return info, nil
+}
+
+// Validate checks the config and fetches every webhook without posting,
+// writing a line per valid webhook and returning the failures.
+func (p *Plugin) Validate(ctx context.Context, w io.Writer) error {
+	p.httpClient = newHTTPClient()
+
+	if err := p.Config.validate(); err != nil {
+		return fmt.Errorf("failed to validate config: %w", err)
+	}
+
+	var errs []error
+	for _, webhook := range p.Config.webhooks() {
+		target := p.forWebhook(webhook)
+		info, err := target.GetWebhook(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", webhook.name(), err))
+			continue
+		}
+		fmt.Fprintln(w, info)
+	}
+	return errors.Join(errs...)
+}
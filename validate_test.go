@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWebhooks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		switch r.URL.Path {
+		case "/api/webhooks/1/token":
+			_, _ = w.Write([]byte(`{"id": "1", "type": 1, "name": "Deploys", "channel_id": "10", "guild_id": "20", "token": "token"}`))
+		case "/api/webhooks/2/revoked":
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message": "401: Unauthorized", "code": 0}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Unknown Webhook", "code": 10015}`))
+		}
+	}))
+	defer srv.Close()
+
+	plugin := Plugin{
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/token",
+			AllowedHosts: testHosts,
+			ThreadID:     "42",
+		},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, plugin.Validate(context.Background(), &out))
+	assert.Equal(t, "webhook 1: name \"Deploys\", channel 10, guild 20, incoming\n", out.String())
+
+	plugin.Config.Webhooks = webhookList{
+		{URL: srv.URL + "/api/webhooks/2/revoked"},
+		{URL: srv.URL + "/api/webhooks/3/deleted"},
+	}
+	out.Reset()
+	err := plugin.Validate(context.Background(), &out)
+	assert.ErrorContains(t, err, "webhook 2: invalid webhook token")
+	assert.ErrorContains(t, err, "webhook 3: unknown webhook")
+	assert.Contains(t, out.String(), "webhook 1: name \"Deploys\"")
+	assert.NotContains(t, out.String(), "revoked")
+}
+
+func TestValidateWebhooksChecksConfig(t *testing.T) {
+	plugin := Plugin{Config: Config{webhookURL: "https://example.com/api/webhooks/1/token"}}
+	assert.ErrorContains(t, plugin.Validate(context.Background(), &bytes.Buffer{}), "not a discord domain")
+}
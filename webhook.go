@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fail policies when sending to multiple webhooks.
+const (
+	// FailAny fails when any webhook could not be notified.
+	FailAny = "any"
+	// FailAll only fails when none of the webhooks could be notified.
+	FailAll = "all"
+)
+
+type (
+	// Webhook is a Discord webhook destination given either as URL or as
+	// ID and token, with optional overrides.
+	Webhook struct {
+		URL       string `yaml:"url"`
+		ID        string `yaml:"id"`
+		Token     string `yaml:"token"`
+		Username  string `yaml:"username"`
+		AvatarURL string `yaml:"avatar_url"`
+		ThreadID  string `yaml:"thread_id"`
+	}
+
+	// webhookList accepts a single webhook or a list of webhooks.
+	webhookList []Webhook
+)
+
+// UnmarshalYAML implements yaml.Unmarshaler. A plain string is read as
+// the webhook URL.
+func (w *Webhook) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*w = Webhook{URL: value.Value}
+		return nil
+	}
+	type plain Webhook
+	return value.Decode((*plain)(w))
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. A plain string is read as a
+// comma-separated list of URLs, the form Drone passes lists of strings in.
+func (l *webhookList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var list webhookList
+		for _, u := range strings.Split(value.Value, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				list = append(list, Webhook{URL: u})
+			}
+		}
+		*l = list
+		return nil
+	}
+	if value.Kind != yaml.SequenceNode {
+		var w Webhook
+		if err := value.Decode(&w); err != nil {
+			return err
+		}
+		*l = webhookList{w}
+		return nil
+	}
+	var list []Webhook
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// unmarshalSetting decodes a YAML or JSON setting given inline or as the
+// path to a file.
+func unmarshalSetting(s string, v any) error {
+	data := []byte(s)
+	if _, err := os.Stat(s); err == nil {
+		if data, err = os.ReadFile(filepath.Clean(s)); err != nil {
+			return fmt.Errorf("failed to read %s: %w", s, err)
+		}
+	}
+	return yaml.Unmarshal(data, v)
+}
+
+// parseWebhooks loads webhooks from a file path or an inline YAML or JSON
+// document.
+func parseWebhooks(s string) ([]Webhook, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var webhooks webhookList
+	if err := unmarshalSetting(s, &webhooks); err != nil {
+		return nil, fmt.Errorf("failed to parse webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// discordHosts are the hosts accepted in webhook URLs without allowlist.
+var discordHosts = map[string]bool{
+	"discord.com":           true,
+	"ptb.discord.com":       true,
+	"canary.discord.com":    true,
+	"discordapp.com":        true,
+	"ptb.discordapp.com":    true,
+	"canary.discordapp.com": true,
+}
+
+var (
+	webhookPathPattern = regexp.MustCompile(`^/api(?:/v\d+)?/webhooks/([^/]+)/([^/]+)/?$`)
+	webhookIDPattern   = regexp.MustCompile(`^\d+$`)
+	webhookTokenChars  = regexp.MustCompile(`^[\w-]+$`)
+)
+
+// validate checks the webhook against the allowed hosts. Hosts on the
+// allowlist may also use plain http, for proxies and test servers.
+func (w *Webhook) validate(allowedHosts []string) error {
+	if w.URL == "" {
+		if w.ID == "" || w.Token == "" {
+			return errors.New("webhook requires a url or an id and token")
+		}
+		return validateCredentials(w.ID, w.Token)
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", redactError(err))
+	}
+
+	allowed := hostAllowed(u, allowedHosts)
+	if !discordHosts[strings.ToLower(u.Host)] && !allowed {
+		return fmt.Errorf("webhook host %s is not a discord domain, add it to the allowed hosts to use it", u.Host)
+	}
+	if u.Scheme != "https" && (!allowed || u.Scheme != "http") {
+		return fmt.Errorf("webhook url must use https, got %s", u.Scheme)
+	}
+
+	m := webhookPathPattern.FindStringSubmatch(u.Path)
+	if m == nil {
+		return errors.New("webhook url must have the form https://discord.com/api/webhooks/{id}/{token}")
+	}
+	if err := validateCredentials(m[1], m[2]); err != nil {
+		return err
+	}
+
+	if (w.ID != "" && w.ID != m[1]) || (w.Token != "" && w.Token != m[2]) {
+		return errors.New("webhook url does not match the webhook id and token")
+	}
+	return nil
+}
+
+// validateCredentials checks the format of a webhook ID and token, without
+// including the token in errors.
+func validateCredentials(id, token string) error {
+	if !webhookIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid webhook id %q, expected a number", id)
+	}
+	if !webhookTokenChars.MatchString(token) {
+		return errors.New("invalid webhook token, expected letters, digits, - and _")
+	}
+	return nil
+}
+
+// hostAllowed reports whether the URL host, with or without port, is on
+// the allowlist.
+func hostAllowed(u *url.URL, allowedHosts []string) bool {
+	for _, h := range allowedHosts {
+		if strings.EqualFold(h, u.Host) || strings.EqualFold(h, u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// name identifies the webhook in logs and errors without its token.
+func (w *Webhook) name() string {
+	if w.ID != "" {
+		return w.ID
+	}
+	if u, err := url.Parse(w.URL); err == nil {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) >= 2 {
+			return parts[len(parts)-2]
+		}
+		return u.Host
+	}
+	return "unknown"
+}
+
+// defaultWebhook returns the webhook configured by the webhook URL or the
+// webhook ID and token.
+func (c *Config) defaultWebhook() (Webhook, bool) {
+	if c.webhookURL == "" && c.WebhookID == "" && c.WebhookToken == "" {
+		return Webhook{}, false
+	}
+	return Webhook{
+		URL:      c.webhookURL,
+		ID:       c.WebhookID,
+		Token:    c.WebhookToken,
+		ThreadID: c.ThreadID,
+	}, true
+}
+
+// destinations returns all configured webhooks.
+func (c *Config) destinations() []Webhook {
+	var webhooks []Webhook
+	if w, ok := c.defaultWebhook(); ok {
+		webhooks = append(webhooks, w)
+	}
+	return append(webhooks, c.Webhooks...)
+}
+
+// forWebhook returns a copy of the plugin sending to the given webhook.
+func (p *Plugin) forWebhook(w Webhook) Plugin {
+	target := *p
+	target.Config.webhookURL = w.URL
+	target.Config.WebhookID = w.ID
+	target.Config.WebhookToken = w.Token
+	target.Config.ThreadID = w.ThreadID
+	target.Config.Webhooks = nil
+	target.Payload.Embeds = append([]EmbedObject(nil), p.Payload.Embeds...)
+	if w.Username != "" {
+		target.Payload.Username = w.Username
+	}
+	if w.AvatarURL != "" {
+		target.Payload.AvatarURL = w.AvatarURL
+	}
+	return target
+}
+
+// fanOut notifies all targets concurrently and applies the fail policy.
+// Dry runs notify the targets one after another so the requests are
+// printed in order.
+func (p *Plugin) fanOut(ctx context.Context, targets []Plugin) error {
+	limit := p.Config.MaxConcurrency
+	if limit <= 0 {
+		limit = 1
+	}
+
+	errs := make([]error, len(targets))
+	notify := func(i int) {
+		t := &targets[i]
+		if err := t.notify(ctx); err != nil {
+			w, _ := t.Config.defaultWebhook()
+			errs[i] = fmt.Errorf("webhook %s: %w", w.name(), err)
+		}
+	}
+
+	if p.Config.DryRun {
+		for i := range targets {
+			notify(i)
+		}
+	} else {
+		var (
+			wg  sync.WaitGroup
+			sem = make(chan struct{}, limit)
+		)
+		for i := range targets {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				notify(i)
+			}()
+		}
+		wg.Wait()
+	}
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if p.Config.FailPolicy == FailAll && len(failed) < len(targets) {
+		for _, err := range failed {
+			log.Printf("ignoring failure: %v", err)
+		}
+		return nil
+	}
+	return errors.Join(failed...)
+}
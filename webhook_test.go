@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWebhooks(t *testing.T) {
+	webhooks, err := parseWebhooks(`
+- https://discord.com/api/webhooks/1/team
+- id: "2"
+  token: ops
+  username: ops-bot
+  thread_id: "42"
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, []Webhook{
+		{URL: "https://discord.com/api/webhooks/1/team"},
+		{ID: "2", Token: "ops", Username: "ops-bot", ThreadID: "42"},
+	}, webhooks)
+	assert.Equal(t, "1", webhooks[0].name())
+	assert.Equal(t, "2", webhooks[1].name())
+
+	webhooks, err = parseWebhooks(`"https://discord.com/api/webhooks/3/release"`)
+	assert.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+	webhooks, err = parseWebhooks("https://discord.com/api/webhooks/1/team, https://discord.com/api/webhooks/2/ops")
+	assert.NoError(t, err)
+	assert.Equal(t, []Webhook{
+		{URL: "https://discord.com/api/webhooks/1/team"},
+		{URL: "https://discord.com/api/webhooks/2/ops"},
+	}, webhooks)
+}
+
+func TestGetWebhookURLWithThread(t *testing.T) {
+	c := Config{WebhookID: "1", WebhookToken: "token", ThreadID: "42"}
+	assert.Equal(t, "https://discord.com/api/webhooks/1/token?thread_id=42", c.GetWebhookURL())
+
+	c = Config{webhookURL: "https://discord.com/api/webhooks/1/token?wait=true", ThreadID: "42"}
+	assert.Equal(t, "https://discord.com/api/webhooks/1/token?wait=true&thread_id=42", c.GetWebhookURL())
+}
+
+func TestExecWithMultipleWebhooks(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+
+	plugin := Plugin{
+		Config: Config{
+			webhookURL:   srv.URL + "/api/webhooks/1/team",
+			AllowedHosts: testHosts,
+			Webhooks: []Webhook{
+				{URL: srv.URL + "/api/webhooks/2/ops", Username: "ops-bot"},
+				{URL: srv.URL + "/api/webhooks/3/release", ThreadID: "42"},
+			},
+			MaxConcurrency: 2,
+			Message:        []string{"hello"},
+		},
+		Payload: Payload{Username: "default-bot"},
+	}
+
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	paths, bodies := rec.requests()
+	sort.Strings(paths)
+	assert.Equal(t, []string{"/api/webhooks/1/team", "/api/webhooks/2/ops", "/api/webhooks/3/release"}, paths)
+	for _, body := range bodies {
+		assert.Contains(t, body, `"content":"hello"`)
+	}
+}
+
+func TestFailPolicy(t *testing.T) {
+	ok, _ := newWebhookServer(t)
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Unknown Webhook", "code": 10015}`))
+	}))
+	defer broken.Close()
+
+	newPlugin := func(policy string, webhooks ...Webhook) Plugin {
+		return Plugin{
+			Config: Config{
+				Webhooks:       webhooks,
+				AllowedHosts:   testHosts,
+				MaxConcurrency: 4,
+				FailPolicy:     policy,
+				Message:        []string{"hello"},
+			},
+		}
+	}
+	good := Webhook{URL: ok.URL + "/api/webhooks/1/good"}
+	bad := Webhook{URL: broken.URL + "/api/webhooks/2/bad"}
+
+	plugin := newPlugin(FailAny, good, bad)
+	err := plugin.Exec(context.Background())
+	assert.ErrorContains(t, err, "webhook 2:")
+	assert.ErrorContains(t, err, "Unknown Webhook")
+
+	plugin = newPlugin(FailAll, good, bad)
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	plugin = newPlugin(FailAll, bad, bad)
+	assert.Error(t, plugin.Exec(context.Background()))
+}
+
+func TestWebhookValidate(t *testing.T) {
+	tests := []struct {
+		webhook Webhook
+		err     string
+	}{
+		{Webhook{URL: "https://discord.com/api/webhooks/123/abc-DEF_1"}, ""},
+		{Webhook{URL: "https://canary.discordapp.com/api/v10/webhooks/123/abc"}, ""},
+		{Webhook{URL: "https://discord.com/api/webhooks/123/abc", ID: "123", Token: "abc"}, ""},
+		{Webhook{URL: "http://127.0.0.1:8080/api/webhooks/1/abc"}, ""},
+		{Webhook{ID: "123", Token: "abc"}, ""},
+		{Webhook{URL: "http://discord.com/api/webhooks/123/abc"}, "webhook url must use https"},
+		{Webhook{URL: "https://discord.example.com/api/webhooks/123/abc"}, "webhook host discord.example.com is not a discord domain"},
+		{Webhook{URL: "https://discord.com/webhooks/123/abc"}, "webhook url must have the form"},
+		{Webhook{URL: "https://discord.com/api/webhooks/123"}, "webhook url must have the form"},
+		{Webhook{URL: "https://discord.com/api/webhooks/abc/token"}, `invalid webhook id "abc"`},
+		{Webhook{URL: "https://discord.com/api/webhooks/123/abc", ID: "456"}, "does not match"},
+		{Webhook{URL: "https://discord.com/api/webhooks/123/abc", Token: "other"}, "does not match"},
+		{Webhook{ID: "123", Token: "a b"}, "invalid webhook token"},
+		{Webhook{ID: "123"}, "webhook requires a url or an id and token"},
+	}
+	for _, tt := range tests {
+		err := tt.webhook.validate([]string{"127.0.0.1"})
+		if tt.err == "" {
+			assert.NoError(t, err, tt.webhook.URL)
+			continue
+		}
+		assert.ErrorContains(t, err, tt.err, tt.webhook.URL)
+		assert.NotContains(t, err.Error(), "abc-DEF")
+	}
+}
+
+func TestValidateDefaultWebhook(t *testing.T) {
+	c := Config{webhookURL: "https://discord.com/api/webhooks/1/token", WebhookID: "2", WebhookToken: "token"}
+	assert.ErrorContains(t, c.validate(), "webhook url does not match the webhook id and token")
+
+	c = Config{webhookURL: "https://hooks.example.com/api/webhooks/1/token", AllowedHosts: []string{"hooks.example.com"}}
+	assert.NoError(t, c.validate())
+
+	c = Config{WebhookID: "id", WebhookToken: "token"}
+	assert.ErrorContains(t, c.validate(), `invalid webhook id "id"`)
+}